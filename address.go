@@ -0,0 +1,153 @@
+package zillean
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+
+	crypto "github.com/GincoInc/go-crypto"
+)
+
+// bech32HRP is the human-readable part of bech32 Zilliqa addresses.
+const bech32HRP = "zil"
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// ToChecksumAddress returns an address with the case of its letters set by the checksum, with the 0x prefix.
+func (z *Zillean) ToChecksumAddress(address string) (string, error) {
+	address = strings.ToLower(strings.TrimPrefix(address, "0x"))
+	if !z.IsAddress(address) {
+		return "", errors.New("invalid address")
+	}
+	addr, _ := hex.DecodeString(address)
+	v := new(big.Int).SetBytes(crypto.Sha256(addr))
+
+	checksum := []byte("0x" + address)
+	for i := 0; i < len(address); i++ {
+		if address[i] >= 'a' && v.Bit(255-6*i) == 1 {
+			checksum[i+2] = address[i] - 'a' + 'A'
+		}
+	}
+	return string(checksum), nil
+}
+
+// IsChecksumAddress checks whether a given string is an address with a valid checksum.
+func (z *Zillean) IsChecksumAddress(address string) bool {
+	checksum, err := z.ToChecksumAddress(address)
+	return err == nil && strings.TrimPrefix(checksum, "0x") == strings.TrimPrefix(address, "0x")
+}
+
+// ToBech32Address returns the bech32 form of an address, such as zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf.
+func (z *Zillean) ToBech32Address(address string) (string, error) {
+	address = strings.TrimPrefix(address, "0x")
+	if !z.IsAddress(address) {
+		return "", errors.New("invalid address")
+	}
+	addr, _ := hex.DecodeString(address)
+	data := convertBits(addr, 8, 5, true)
+
+	var b strings.Builder
+	b.WriteString(bech32HRP + "1")
+	for _, d := range append(data, bech32Checksum(bech32HRP, data)...) {
+		b.WriteByte(bech32Charset[d])
+	}
+	return b.String(), nil
+}
+
+// FromBech32Address returns the lowercase hex form of a bech32 address.
+func (z *Zillean) FromBech32Address(address string) (string, error) {
+	if strings.ToLower(address) != address && strings.ToUpper(address) != address {
+		return "", errors.New("invalid bech32 address, mixed case")
+	}
+	address = strings.ToLower(address)
+	sep := strings.LastIndexByte(address, '1')
+	if sep < 1 || address[:sep] != bech32HRP || len(address)-sep-1 < 6 {
+		return "", errors.New("invalid bech32 address")
+	}
+
+	data := make([]byte, 0, len(address)-sep-1)
+	for i := sep + 1; i < len(address); i++ {
+		d := strings.IndexByte(bech32Charset, address[i])
+		if d < 0 {
+			return "", errors.New("invalid bech32 address, invalid character")
+		}
+		data = append(data, byte(d))
+	}
+	if bech32Polymod(append(bech32ExpandHRP(bech32HRP), data...)) != 1 {
+		return "", errors.New("invalid bech32 address, invalid checksum")
+	}
+
+	addr := convertBits(data[:len(data)-6], 5, 8, false)
+	if addr == nil || len(addr) != 20 {
+		return "", errors.New("invalid bech32 address, invalid length")
+	}
+	return hex.EncodeToString(addr), nil
+}
+
+// IsBech32Address checks whether a given string is a bech32 address or not.
+func (z *Zillean) IsBech32Address(address string) bool {
+	_, err := z.FromBech32Address(address)
+	return err == nil
+}
+
+// convertBits regroups data from fromBits to toBits bits per byte. It returns nil if data cannot be regrouped
+// without padding and pad is false.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	var acc, bits uint
+	maxv := uint(1)<<toBits - 1
+	result := []byte{}
+	for _, b := range data {
+		acc = acc<<fromBits | uint(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil
+	}
+	return result
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if top>>uint(i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32ExpandHRP(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(bech32ExpandHRP(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(polymod >> uint(5*(5-i)) & 31)
+	}
+	return checksum
+}
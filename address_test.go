@@ -0,0 +1,52 @@
+package zillean
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestZillean_ToChecksumAddress(t *testing.T) {
+	Convey("returns the checksum address", t, func() {
+		z := NewZillean(localNet)
+		address, err := z.ToChecksumAddress("0x4BAF5FADA8E5DB92C3D3242618C5B47133AE003C")
+		So(err, ShouldBeNil)
+		So(address, ShouldEqual, "0x4BAF5faDA8e5Db92C3d3242618c5B47133AE003C")
+		address, err = z.ToChecksumAddress("448261915a80cde9bde7c7a791685200d3a0bf4e")
+		So(err, ShouldBeNil)
+		So(address, ShouldEqual, "0x448261915a80cdE9BDE7C7a791685200D3A0bf4E")
+
+		So(z.IsChecksumAddress("0x4BAF5faDA8e5Db92C3d3242618c5B47133AE003C"), ShouldBeTrue)
+		So(z.IsChecksumAddress("0x4baf5fada8e5db92c3d3242618c5b47133ae003c"), ShouldBeFalse)
+
+		_, err = z.ToChecksumAddress("0x4BAF5FADA8E5DB92C3D3242618C5B47133AE00")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestZillean_ToBech32Address(t *testing.T) {
+	Convey("converts between hex and bech32 addresses", t, func() {
+		z := NewZillean(localNet)
+		address, err := z.ToBech32Address("0x1d19918a737306218b5cbb3241fcdcbd998c3a72")
+		So(err, ShouldBeNil)
+		So(address, ShouldEqual, "zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf")
+
+		hex, err := z.FromBech32Address("zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf")
+		So(err, ShouldBeNil)
+		So(hex, ShouldEqual, "1d19918a737306218b5cbb3241fcdcbd998c3a72")
+
+		for _, vector := range testVectors {
+			address, err := z.ToBech32Address(vector.address)
+			So(err, ShouldBeNil)
+			hex, err := z.FromBech32Address(address)
+			So(err, ShouldBeNil)
+			So(hex, ShouldEqual, vector.address)
+		}
+
+		So(z.IsBech32Address("ZIL1R5VERZNNWVRZRZ6UHVEYRLXUHKVCCWNJU4AEHF"), ShouldBeTrue)
+		So(z.IsBech32Address("zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehg"), ShouldBeFalse)
+		So(z.IsBech32Address("Zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf"), ShouldBeFalse)
+		So(z.IsBech32Address("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"), ShouldBeFalse)
+		So(z.IsBech32Address("1d19918a737306218b5cbb3241fcdcbd998c3a72"), ShouldBeFalse)
+	})
+}
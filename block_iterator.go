@@ -0,0 +1,192 @@
+package zillean
+
+import "fmt"
+
+// BlockOrder is the order in which a BlockIterator walks blocks.
+type BlockOrder int
+
+// Orders of a BlockIterator.
+const (
+	NewestFirst BlockOrder = iota
+	OldestFirst
+)
+
+// maxListingRetries is the number of times a BlockIterator fetches a page again when new blocks shifted
+// the block it wants next to another page.
+const maxListingRetries = 3
+
+// BlockIterator walks the blocks of a paginated block listing, such as RPC.TxBlockListing or RPC.DSBlockListing,
+// across pages.
+//
+// Pages shift as new blocks arrive, so a BlockIterator finds the page of the block it wants next from its number
+// instead of counting pages, which neither returns a block twice nor skips one.
+type BlockIterator struct {
+	// StopAt is the last block returned. If it is nil, a newest-to-oldest walk ends at the genesis block.
+	// An oldest-to-newest walk starts at the genesis block and ends at the newest block when the walk started
+	// at the latest.
+	StopAt *uint64
+	// StartAt is the first block returned. If it is nil, a newest-to-oldest walk starts at the newest block
+	// and an oldest-to-newest walk at the genesis block.
+	StartAt *uint64
+
+	listing  func(pageNumber int64) (*ListedBlocks, error)
+	order    BlockOrder
+	started  bool
+	done     bool
+	pageSize uint64
+	newest   uint64
+	growth   uint64
+	next     uint64
+	end      uint64
+	pending  []ListedBlock
+	block    ListedBlock
+	err      error
+}
+
+// NewBlockIterator returns a new BlockIterator which walks the blocks of listing in order.
+func NewBlockIterator(listing func(pageNumber int64) (*ListedBlocks, error), order BlockOrder) *BlockIterator {
+	return &BlockIterator{listing: listing, order: order}
+}
+
+// Next advances to the next block, which is then returned by Block. It returns false at the end of the walk
+// or on an error, which is returned by Err.
+func (it *BlockIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if len(it.pending) == 0 {
+		var err error
+		if !it.started {
+			err = it.start()
+		} else {
+			err = it.fetch()
+		}
+		if err != nil {
+			it.err, it.done = err, true
+		}
+		if it.done {
+			return false
+		}
+	}
+
+	it.block, it.pending = it.pending[0], it.pending[1:]
+	blockNum := uint64(it.block.BlockNum)
+	switch {
+	case blockNum == it.end:
+		it.done = true
+	case it.order == NewestFirst:
+		it.next = blockNum - 1
+	default:
+		it.next = blockNum + 1
+	}
+	return true
+}
+
+// Block returns the current block.
+func (it *BlockIterator) Block() ListedBlock {
+	return it.block
+}
+
+// Err returns the error which ended the walk, if any.
+func (it *BlockIterator) Err() error {
+	return it.err
+}
+
+// start fetches the first page of the walk, and sets the first and last blocks of the walk.
+func (it *BlockIterator) start() error {
+	it.started = true
+	first, err := it.listing(1)
+	if err != nil {
+		return err
+	}
+	if len(first.Data) == 0 {
+		it.done = true
+		return nil
+	}
+	it.pageSize = uint64(len(first.Data))
+	it.newest = uint64(first.Data[0].BlockNum)
+
+	if it.order == NewestFirst {
+		it.next = it.newest
+		if it.StartAt != nil && *it.StartAt < it.next {
+			it.next = *it.StartAt
+		}
+		if it.StopAt != nil {
+			it.end = *it.StopAt
+		}
+		if it.next < it.end {
+			it.done = true
+			return nil
+		}
+	} else {
+		it.next = 0
+		if it.StartAt != nil {
+			it.next = *it.StartAt
+		}
+		it.end = it.newest
+		if it.StopAt != nil && *it.StopAt < it.end {
+			it.end = *it.StopAt
+		}
+		if it.next > it.end {
+			it.done = true
+			return nil
+		}
+	}
+	if it.pending = it.run(first.Data); len(it.pending) == 0 {
+		return it.fetch()
+	}
+	return nil
+}
+
+// fetch fetches the page holding the next block, and sets pending to the blocks of the walk on it. It expects
+// as many new blocks before the page as arrived between the last two pages.
+func (it *BlockIterator) fetch() error {
+	for i := 0; i < maxListingRetries; i++ {
+		page := int64((it.newest+it.growth-it.next)/it.pageSize) + 1
+		listed, err := it.listing(page)
+		if err != nil {
+			return err
+		}
+		if len(listed.Data) > 0 {
+			newest := uint64(listed.Data[0].BlockNum) + uint64(page-1)*it.pageSize
+			it.growth = 0
+			if newest > it.newest {
+				it.growth = newest - it.newest
+			}
+			it.newest = newest
+		}
+		if it.pending = it.run(listed.Data); len(it.pending) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("block listing does not contain block %d", it.next)
+}
+
+// run returns the consecutive blocks of the walk on a page, starting with the next block.
+func (it *BlockIterator) run(data []ListedBlock) []ListedBlock {
+	var blocks []ListedBlock
+	want := it.next
+	for i := range data {
+		b := data[i]
+		if it.order == OldestFirst {
+			b = data[len(data)-1-i]
+		}
+		blockNum := uint64(b.BlockNum)
+		if (it.order == NewestFirst && blockNum > want) || (it.order == OldestFirst && blockNum < want) {
+			continue
+		}
+		if blockNum != want {
+			break
+		}
+		blocks = append(blocks, b)
+		if want == it.end {
+			break
+		}
+		if it.order == NewestFirst {
+			want--
+		} else {
+			want++
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,147 @@
+package zillean
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testListing is a block listing of blocks from 0 to newest with 10 blocks a page, which grows by
+// growth blocks on every call.
+type testListing struct {
+	newest int64
+	growth int64
+	calls  int
+	failAt int
+}
+
+func (l *testListing) page(pageNumber int64) (*ListedBlocks, error) {
+	l.calls++
+	if l.calls == l.failAt {
+		return nil, errors.New("listing failed")
+	}
+	defer func() { l.newest += l.growth }()
+
+	result := &ListedBlocks{MaxPages: (l.newest + 10) / 10}
+	for blockNum := l.newest - (pageNumber-1)*10; blockNum >= 0 && blockNum > l.newest-pageNumber*10; blockNum-- {
+		result.Data = append(result.Data, ListedBlock{BlockNum: Number(blockNum), Hash: Hash{byte(blockNum)}})
+	}
+	return result, nil
+}
+
+func walk(it *BlockIterator) []uint64 {
+	var blocks []uint64
+	for it.Next() {
+		blocks = append(blocks, uint64(it.Block().BlockNum))
+	}
+	return blocks
+}
+
+func blockRange(from, to int) []uint64 {
+	var blocks []uint64
+	for i := from; ; {
+		blocks = append(blocks, uint64(i))
+		if i == to {
+			return blocks
+		}
+		if from < to {
+			i++
+		} else {
+			i--
+		}
+	}
+}
+
+func TestBlockIterator(t *testing.T) {
+	Convey("walks blocks newest-to-oldest across pages", t, func() {
+		listing := &testListing{newest: 24}
+		it := NewBlockIterator(listing.page, NewestFirst)
+		So(walk(it), ShouldResemble, blockRange(24, 0))
+		So(it.Err(), ShouldBeNil)
+		So(it.Next(), ShouldBeFalse)
+	})
+
+	Convey("walks blocks oldest-to-newest across pages", t, func() {
+		listing := &testListing{newest: 24}
+		it := NewBlockIterator(listing.page, OldestFirst)
+		So(walk(it), ShouldResemble, blockRange(0, 24))
+		So(it.Err(), ShouldBeNil)
+	})
+
+	Convey("returns every block once while new blocks shift the pages", t, func() {
+		listing := &testListing{newest: 44, growth: 3}
+		it := NewBlockIterator(listing.page, NewestFirst)
+		So(walk(it), ShouldResemble, blockRange(44, 0))
+		So(it.Err(), ShouldBeNil)
+
+		listing = &testListing{newest: 44, growth: 7}
+		it = NewBlockIterator(listing.page, OldestFirst)
+		So(walk(it), ShouldResemble, blockRange(0, 44))
+		So(it.Err(), ShouldBeNil)
+	})
+
+	Convey("stops at StopAt", t, func() {
+		stopAt := uint64(15)
+		it := NewBlockIterator((&testListing{newest: 24}).page, NewestFirst)
+		it.StopAt = &stopAt
+		So(walk(it), ShouldResemble, blockRange(24, 15))
+
+		stopAt = 12
+		it = NewBlockIterator((&testListing{newest: 24, growth: 1}).page, OldestFirst)
+		it.StopAt = &stopAt
+		So(walk(it), ShouldResemble, blockRange(0, 12))
+
+		stopAt = 30
+		it = NewBlockIterator((&testListing{newest: 24}).page, NewestFirst)
+		it.StopAt = &stopAt
+		So(walk(it), ShouldBeEmpty)
+		So(it.Err(), ShouldBeNil)
+	})
+
+	Convey("starts at StartAt", t, func() {
+		startAt, stopAt := uint64(17), uint64(8)
+		it := NewBlockIterator((&testListing{newest: 44, growth: 2}).page, NewestFirst)
+		it.StartAt, it.StopAt = &startAt, &stopAt
+		So(walk(it), ShouldResemble, blockRange(17, 8))
+
+		startAt, stopAt = 8, 27
+		it = NewBlockIterator((&testListing{newest: 44, growth: 2}).page, OldestFirst)
+		it.StartAt, it.StopAt = &startAt, &stopAt
+		So(walk(it), ShouldResemble, blockRange(8, 27))
+		So(it.Err(), ShouldBeNil)
+
+		startAt = 50
+		it = NewBlockIterator((&testListing{newest: 44}).page, OldestFirst)
+		it.StartAt = &startAt
+		So(walk(it), ShouldBeEmpty)
+	})
+
+	Convey("returns the error of the listing", t, func() {
+		it := NewBlockIterator((&testListing{newest: 24, failAt: 2}).page, NewestFirst)
+		So(walk(it), ShouldResemble, blockRange(24, 15))
+		So(it.Err(), ShouldBeError, "listing failed")
+	})
+
+	Convey("walks the TX block listing of a node", t, func() {
+		listing := &testListing{newest: 12}
+		node := newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+			var args []int64
+			json.Unmarshal(params, &args)
+			if method != "TxBlockListing" {
+				return nil, fmt.Sprintf("unexpected method %s", method)
+			}
+			result, _ := listing.page(args[0])
+			return result, ""
+		})
+		defer node.Close()
+
+		it := NewBlockIterator(NewRPC(node.URL).TxBlockListing, NewestFirst)
+		So(it.Next(), ShouldBeTrue)
+		So(it.Block().BlockNum, ShouldEqual, Number(12))
+		So(it.Block().Hash.String(), ShouldEqual, "0c")
+		So(walk(it), ShouldResemble, blockRange(11, 0))
+	})
+}
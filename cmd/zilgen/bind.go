@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/GincoInc/zillean"
+)
+
+// goType describes how a Scilla type is represented in the generated Go code.
+type goType struct {
+	// Name is the Go type.
+	Name string
+	// Value converts a Go value into a zillean.Value, with %s replaced by the Go expression.
+	Value string
+	// Imports lists the packages needed by Name and Value.
+	Imports []string
+}
+
+var goTypes = map[string]goType{
+	"Uint32":  {"uint32", "zillean.Uint32(%s)", nil},
+	"Uint64":  {"uint64", "zillean.Uint64(%s)", nil},
+	"Uint128": {"*big.Int", "zillean.Uint128(%s)", []string{"math/big"}},
+	"Uint256": {"*big.Int", "zillean.Uint256(%s)", []string{"math/big"}},
+	"Int32":   {"int32", "zillean.Int32(%s)", nil},
+	"Int64":   {"int64", "zillean.Int64(%s)", nil},
+	"Int128":  {"*big.Int", "zillean.Int128(%s)", []string{"math/big"}},
+	"Int256":  {"*big.Int", "zillean.Int256(%s)", []string{"math/big"}},
+	"BNum":    {"uint64", "zillean.BNum(%s)", nil},
+	"String":  {"string", "zillean.String(%s)", nil},
+	"ByStr20": {"string", "zillean.ByStr20(%s)", nil},
+	"Bool":    {"bool", "zillean.Bool(%s)", nil},
+}
+
+// lookupGoType returns the Go representation of a Scilla type. Types without a native Go
+// representation, such as maps, lists and ADTs, are passed as zillean.Value.
+func lookupGoType(scillaType string) goType {
+	if t, ok := goTypes[scillaType]; ok {
+		return t
+	}
+	if strings.HasPrefix(scillaType, "ByStr") && !strings.Contains(scillaType, " ") {
+		return goType{"string", "zillean.ByStr(%s)", nil}
+	}
+	return goType{"zillean.Value", "%s", nil}
+}
+
+// stateGoType returns the Go type which a state variable of a Scilla type is decoded into by
+// SmartContractState.Unmarshal, and whether it needs math/big. Option becomes a pointer which is nil
+// for None, and types without a native Go representation, such as user-defined ADTs, become interface{}.
+func stateGoType(scillaType string) (string, bool) {
+	head, args := splitType(scillaType)
+	switch {
+	case head == "Option" && len(args) == 1:
+		elem, big := stateGoType(args[0])
+		if strings.HasPrefix(elem, "*") || strings.HasPrefix(elem, "[]") || strings.HasPrefix(elem, "map[") || elem == "interface{}" {
+			return elem, big
+		}
+		return "*" + elem, big
+	case head == "List" && len(args) == 1:
+		elem, big := stateGoType(args[0])
+		return "[]" + elem, big
+	case head == "Map" && len(args) == 2:
+		key, _ := stateGoType(args[0])
+		if key == "*big.Int" {
+			// Big integer keys are decoded into their decimal strings, since *big.Int is not comparable.
+			key = "string"
+		}
+		val, big := stateGoType(args[1])
+		return "map[" + key + "]" + val, big
+	case len(args) > 0:
+		return "interface{}", false
+	}
+	t := lookupGoType(head)
+	if t.Name == "zillean.Value" {
+		return "interface{}", false
+	}
+	return t.Name, len(t.Imports) > 0
+}
+
+// splitType splits a Scilla type of the form used in the Scilla JSON into its name and type arguments,
+// e.g. Map (ByStr20) (Uint128) into Map and [ByStr20 Uint128].
+func splitType(scillaType string) (string, []string) {
+	i := strings.Index(scillaType, " (")
+	if i < 0 {
+		return scillaType, nil
+	}
+	var args []string
+	depth, start := 0, 0
+	for j, r := range scillaType[i:] {
+		switch r {
+		case '(':
+			if depth == 0 {
+				start = i + j + 1
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				args = append(args, scillaType[start:i+j])
+			}
+		}
+	}
+	return scillaType[:i], args
+}
+
+type bindingArg struct {
+	Name   string
+	Vname  string
+	GoType string
+	Value  string
+}
+
+type bindingTransition struct {
+	Name   string
+	Method string
+	Args   []bindingArg
+}
+
+type bindingField struct {
+	Vname  string
+	Type   string
+	Method string
+	GoType string
+}
+
+type binding struct {
+	Package     string
+	Type        string
+	Interface   *zillean.ContractInterface
+	Code        string
+	Imports     []string
+	InitArgs    []bindingArg
+	Transitions []bindingTransition
+	Fields      []bindingField
+}
+
+// reservedArgs are the names of the fixed arguments and local variables of the generated methods,
+// and of the packages they refer to.
+var reservedArgs = map[string]bool{
+	"ctx": true, "zil": true, "privateKey": true, "zilAmount": true, "opts": true, "c": true,
+	"params": true, "result": true, "err": true,
+	"zillean": true, "big": true, "fmt": true, "context": true,
+}
+
+// generate returns the Go source of the binding to a contract. code may be empty, in which case
+// no deploy function is generated.
+func generate(pkg, typeName string, c *zillean.ContractInterface, code string) ([]byte, error) {
+	if typeName == "" {
+		typeName = exportedName(c.Name)
+	}
+	b := &binding{
+		Package:   pkg,
+		Type:      typeName,
+		Interface: c,
+		Code:      code,
+	}
+	imports := map[string]bool{}
+
+	if code != "" {
+		b.InitArgs = bindArgs(c.Params, imports)
+		imports["context"] = true
+	}
+
+	methods := map[string]bool{"Address": true}
+	for _, t := range c.Transitions {
+		// Each transition has a method and a Pack method, so both names must be free.
+		method := exportedName(t.Name)
+		for methods[method] || methods["Pack"+method] {
+			method += "_"
+		}
+		methods[method], methods["Pack"+method] = true, true
+		b.Transitions = append(b.Transitions, bindingTransition{t.Name, method, bindArgs(t.Params, imports)})
+		imports["context"] = true
+	}
+	for _, f := range c.Fields {
+		goType, big := stateGoType(f.Type)
+		b.Fields = append(b.Fields, bindingField{
+			Vname:  f.Name,
+			Type:   f.Type,
+			Method: uniqueName("Get"+exportedName(f.Name), methods),
+			GoType: goType,
+		})
+		if big {
+			imports["math/big"] = true
+		}
+	}
+	if len(b.Fields) > 0 {
+		imports["fmt"] = true
+	}
+
+	for imp := range imports {
+		b.Imports = append(b.Imports, imp)
+	}
+	sort.Strings(b.Imports)
+
+	var buf bytes.Buffer
+	if err := bindingTemplate.Execute(&buf, b); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go code: %v", err)
+	}
+	return src, nil
+}
+
+func bindArgs(defs []zillean.VarDef, imports map[string]bool) []bindingArg {
+	var args []bindingArg
+	used := map[string]bool{}
+	for name := range reservedArgs {
+		used[name] = true
+	}
+	for _, def := range defs {
+		t := lookupGoType(def.Type)
+		for _, imp := range t.Imports {
+			imports[imp] = true
+		}
+		name := uniqueName(unexportedName(def.Name), used)
+		args = append(args, bindingArg{
+			Name:   name,
+			Vname:  def.Name,
+			GoType: t.Name,
+			Value:  fmt.Sprintf(t.Value, name),
+		})
+	}
+	return args
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	for used[name] {
+		name += "_"
+	}
+	used[name] = true
+	return name
+}
+
+// exportedName converts a Scilla identifier such as total_supply into TotalSupply.
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '\'' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// unexportedName converts a Scilla identifier such as init_supply into initSupply.
+func unexportedName(name string) string {
+	exported := []rune(exportedName(name))
+	exported[0] = unicode.ToLower(exported[0])
+	ident := string(exported)
+	if isGoKeyword(ident) {
+		return ident + "_"
+	}
+	return ident
+}
+
+func isGoKeyword(s string) bool {
+	switch s {
+	case "break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for",
+		"func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select",
+		"struct", "switch", "type", "var":
+		return true
+	}
+	return false
+}
+
+var bindingTemplate = template.Must(template.New("binding").Funcs(template.FuncMap{
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+}).Parse(`// Code generated by zilgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{quote .}}
+{{- end}}
+
+	"github.com/GincoInc/zillean"
+)
+
+// {{.Type}}Interface is the interface of the {{.Interface.Name}} contract.
+var {{.Type}}Interface = &zillean.ContractInterface{
+	Name:          {{quote .Interface.Name}},
+	ScillaVersion: {{.Interface.ScillaVersion}},
+	Params: []zillean.VarDef{
+{{- range .Interface.Params}}
+		{Name: {{quote .Name}}, Type: {{quote .Type}}},
+{{- end}}
+	},
+	Fields: []zillean.VarDef{
+{{- range .Interface.Fields}}
+		{Name: {{quote .Name}}, Type: {{quote .Type}}},
+{{- end}}
+	},
+	Transitions: []zillean.TransitionDef{
+{{- range .Interface.Transitions}}
+		{Name: {{quote .Name}}, Params: []zillean.VarDef{
+		{{- range .Params}}
+			{Name: {{quote .Name}}, Type: {{quote .Type}}},
+		{{- end}}
+		}},
+{{- end}}
+	},
+}
+{{if .Code}}
+// {{.Type}}Code is the Scilla source code of the {{.Interface.Name}} contract.
+const {{.Type}}Code = {{quote .Code}}
+{{end}}
+// {{.Type}} is a binding to a deployed {{.Interface.Name}} contract.
+type {{.Type}} struct {
+	Address string
+	zil     *zillean.Zillean
+}
+
+// New{{.Type}} returns a binding to the {{.Interface.Name}} contract deployed at an address.
+func New{{.Type}}(address string, zil *zillean.Zillean) *{{.Type}} {
+	return &{{.Type}}{
+		Address: address,
+		zil:     zil,
+	}
+}
+{{if .Code}}
+// Deploy{{.Type}} deploys a new {{.Interface.Name}} contract and returns a binding to it.
+func Deploy{{.Type}}(ctx context.Context, zil *zillean.Zillean, privateKey string{{range .InitArgs}}, {{.Name}} {{.GoType}}{{end}}, opts *zillean.TxOptions) (*{{.Type}}, *zillean.DeployResult, error) {
+	params := []zillean.Param{
+{{- range .InitArgs}}
+		{Vname: {{quote .Vname}}, Value: {{.Value}}},
+{{- end}}
+	}
+	if err := {{.Type}}Interface.ValidateInit(params); err != nil {
+		return nil, nil, err
+	}
+	result, err := zil.DeployContract(ctx, privateKey, {{.Type}}Code, params, opts)
+	if err != nil {
+		return nil, result, err
+	}
+	return New{{.Type}}(result.ContractAddress, zil), result, nil
+}
+{{end}}
+{{- range .Transitions}}
+// Pack{{.Method}} returns the RawTransaction.Data calling the {{.Name}} transition.
+func (c *{{$.Type}}) Pack{{.Method}}({{range $i, $arg := .Args}}{{if $i}}, {{end}}{{$arg.Name}} {{$arg.GoType}}{{end}}) (string, error) {
+	params := []zillean.Param{
+{{- range .Args}}
+		{Vname: {{quote .Vname}}, Value: {{.Value}}},
+{{- end}}
+	}
+	if err := {{$.Type}}Interface.ValidateCall({{quote .Name}}, params); err != nil {
+		return "", err
+	}
+	return zillean.CallData({{quote .Name}}, params)
+}
+
+// {{.Method}} calls the {{.Name}} transition, sending zilAmount (in Qa) to the contract.
+func (c *{{$.Type}}) {{.Method}}(ctx context.Context, privateKey string{{range .Args}}, {{.Name}} {{.GoType}}{{end}}, zilAmount string, opts *zillean.TxOptions) (*zillean.ContractResult, error) {
+	params := []zillean.Param{
+{{- range .Args}}
+		{Vname: {{quote .Vname}}, Value: {{.Value}}},
+{{- end}}
+	}
+	if err := {{$.Type}}Interface.ValidateCall({{quote .Name}}, params); err != nil {
+		return nil, err
+	}
+	return c.zil.CallContract(ctx, privateKey, c.Address, {{quote .Name}}, params, zilAmount, opts)
+}
+{{end}}
+{{- range .Fields}}
+// {{.Method}} returns the {{.Vname}} field of the contract.
+func (c *{{$.Type}}) {{.Method}}() ({{.GoType}}, error) {
+	var value {{.GoType}}
+	err := c.field({{quote .Vname}}, &value)
+	return value, err
+}
+{{end}}
+{{- if .Fields}}
+
+func (c *{{.Type}}) field(vname string, v interface{}) error {
+	states, err := c.zil.RPC.GetSmartContractState(c.Address)
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		if state.Vname == vname {
+			return state.Unmarshal(v)
+		}
+	}
+	return fmt.Errorf("missing field %s", vname)
+}
+{{- end}}
+`))
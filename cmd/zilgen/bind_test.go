@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/GincoInc/zillean"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerate(t *testing.T) {
+	Convey("returns the binding to the contract", t, func() {
+		code, err := ioutil.ReadFile(filepath.Join("testdata", "HelloWorld.scilla"))
+		So(err, ShouldBeNil)
+		c, err := zillean.ParseContract(string(code))
+		So(err, ShouldBeNil)
+
+		src, err := generate("hello", "", c, string(code))
+		So(err, ShouldBeNil)
+
+		golden := filepath.Join("testdata", "hello_world.go.golden")
+		if *update {
+			So(ioutil.WriteFile(golden, src, 0644), ShouldBeNil)
+		}
+		expected, err := ioutil.ReadFile(golden)
+		So(err, ShouldBeNil)
+		So(string(src), ShouldEqual, string(expected))
+	})
+
+	Convey("returns a binding which compiles", t, func() {
+		gobin, err := exec.LookPath("go")
+		if err != nil {
+			SkipSo(err, ShouldBeNil)
+			return
+		}
+		// Directories under testdata are only built when named explicitly.
+		dir, err := ioutil.TempDir("testdata", "build")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		src, err := ioutil.ReadFile(filepath.Join("testdata", "hello_world.go.golden"))
+		So(err, ShouldBeNil)
+		So(ioutil.WriteFile(filepath.Join(dir, "hello_world.go"), src, 0644), ShouldBeNil)
+
+		out, err := exec.Command(gobin, "build", "./"+filepath.ToSlash(dir)).CombinedOutput()
+		So(string(out), ShouldBeEmpty)
+		So(err, ShouldBeNil)
+	})
+}
+
+func TestGenerate_names(t *testing.T) {
+	Convey("gives every transition a method and a Pack method of its own", t, func() {
+		c, err := zillean.ParseContract(`scilla_version 0
+contract Clash ()
+field address : ByStr20 = 0x0000000000000000000000000000000000000000
+transition PackFoo ()
+end
+transition Foo ()
+end
+transition Address ()
+end
+`)
+		So(err, ShouldBeNil)
+		src, err := generate("clash", "", c, "")
+		So(err, ShouldBeNil)
+
+		file, err := parser.ParseFile(token.NewFileSet(), "clash.go", src, 0)
+		So(err, ShouldBeNil)
+		var methods []string
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil {
+				methods = append(methods, fn.Name.Name)
+			}
+		}
+		So(methods, ShouldResemble, []string{"PackPackFoo", "PackFoo", "PackFoo_", "Foo_", "PackAddress_", "Address_", "GetAddress", "field"})
+	})
+}
+
+func TestExportedName(t *testing.T) {
+	Convey("converts Scilla identifiers into Go identifiers", t, func() {
+		So(exportedName("total_supply"), ShouldEqual, "TotalSupply")
+		So(exportedName("setHello"), ShouldEqual, "SetHello")
+		So(exportedName("_balance"), ShouldEqual, "Balance")
+		So(unexportedName("init_supply"), ShouldEqual, "initSupply")
+		So(unexportedName("type"), ShouldEqual, "type_")
+	})
+}
+
+func TestStateGoType(t *testing.T) {
+	Convey("returns the Go types which state variables are decoded into", t, func() {
+		for scillaType, expected := range map[string]string{
+			"Uint128":          "*big.Int",
+			"BNum":             "uint64",
+			"ByStr32":          "string",
+			"Option (Uint32)":  "*uint32",
+			"Option (Uint128)": "*big.Int",
+			"List (ByStr20)":   "[]string",
+			"Map (ByStr20) (Map (ByStr20) (Uint128))": "map[string]map[string]*big.Int",
+			"Map (Uint256) (Bool)":                    "map[string]bool",
+			"Map (Uint64) (Pair (ByStr20) (Uint128))": "map[uint64]interface{}",
+			"Status": "interface{}",
+		} {
+			goType, _ := stateGoType(scillaType)
+			So(goType, ShouldEqual, expected)
+		}
+	})
+}
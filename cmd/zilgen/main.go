@@ -0,0 +1,81 @@
+// Command zilgen generates Go bindings to Scilla contracts.
+//
+// The contract is read from a Scilla source file, or fetched from a node by its address:
+//
+//	zilgen -scilla FungibleToken.scilla -pkg token -out token.go
+//	zilgen -address 6c1169e8a77d34d6d615862db5f62f0a9791cb9f -endpoint https://api.zilliqa.com -pkg hello
+//
+// The generated package has a struct per contract with typed methods per transition,
+// which build RawTransaction.Data or call the transition, and typed accessors for the contract state.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/GincoInc/zillean"
+)
+
+func main() {
+	var (
+		scillaFile = flag.String("scilla", "", "path to the Scilla source file of the contract")
+		address    = flag.String("address", "", "address of a deployed contract whose code is fetched from -endpoint")
+		endpoint   = flag.String("endpoint", "https://api.zilliqa.com", "JSON-RPC endpoint of a Zilliqa node")
+		pkg        = flag.String("pkg", "", "package name of the generated code (required)")
+		typeName   = flag.String("type", "", "Go type name of the contract binding (defaults to the contract name)")
+		out        = flag.String("out", "", "output file (defaults to stdout)")
+	)
+	flag.Parse()
+
+	if err := run(*scillaFile, *address, *endpoint, *pkg, *typeName, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "zilgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(scillaFile, address, endpoint, pkg, typeName, out string) error {
+	if pkg == "" {
+		return fmt.Errorf("-pkg is required")
+	}
+
+	var code string
+	switch {
+	case scillaFile != "" && address != "":
+		return fmt.Errorf("-scilla and -address are mutually exclusive")
+	case scillaFile != "":
+		data, err := ioutil.ReadFile(scillaFile)
+		if err != nil {
+			return err
+		}
+		code = string(data)
+	case address != "":
+		c, err := zillean.NewRPC(endpoint).GetSmartContractCode(address)
+		if err != nil {
+			return err
+		}
+		code = c
+	default:
+		return fmt.Errorf("either -scilla or -address is required")
+	}
+
+	c, err := zillean.ParseContract(code)
+	if err != nil {
+		return err
+	}
+	// A contract fetched by address is already deployed, so the deploy function is omitted.
+	if address != "" {
+		code = ""
+	}
+	src, err := generate(pkg, typeName, c, code)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(out, src, 0644)
+}
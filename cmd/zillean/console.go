@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GincoInc/zillean"
+	"github.com/peterh/liner"
+)
+
+const consoleHelp = `Call any RPC method with its arguments, and save results in variables:
+
+  GetBalance 4baf5fada8e5db92c3d3242618c5b47133ae003c
+  block = GetTxBlock 12345
+  GetTransactionsForTxBlock $block.header.BlockNum
+
+Arguments are separated by spaces, and may be quoted with ' or ". Arguments of slice or struct type are JSON.
+$name is replaced by a variable, and $name.field.0 by a field or element of it.
+
+Commands:
+  help                 show this help
+  methods              list the RPC methods
+  vars                 show the variables
+  load FILE            load the private key of a keystore file
+  account              show the address and balance of the loaded key
+  sign TO AMOUNT       sign a transfer in Qa from the loaded key, filling the nonce and gas price from the node
+  send TO AMOUNT       send a transfer in Qa from the loaded key and wait for the confirmation
+  exit                 leave the console`
+
+var (
+	assignmentRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+	// assignedRegexp matches the start of an assignment before the method name.
+	assignedRegexp  = regexp.MustCompile(`^\s*[A-Za-z_][A-Za-z0-9_]*\s*=\s*$`)
+	consoleCommands = []string{"help", "methods", "vars", "load", "account", "sign", "send", "exit"}
+)
+
+// console evaluates the lines of the interactive console.
+type console struct {
+	z    *zillean.Zillean
+	vars map[string]interface{}
+	// passphrase reads the passphrase of a keystore.
+	passphrase func(prompt string) (string, error)
+	privateKey string
+}
+
+func newConsole(z *zillean.Zillean, passphrase func(prompt string) (string, error)) *console {
+	return &console{z: z, vars: map[string]interface{}{}, passphrase: passphrase}
+}
+
+func (c *cli) console(args []string) (interface{}, error) {
+	flags := flag.NewFlagSet("console", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	con := newConsole(c.z, line.PasswordPrompt)
+	line.SetCompleter(con.complete)
+
+	fmt.Fprintln(c.stdout, `Type "help" for the commands.`)
+	for {
+		input, err := line.Prompt("zillean> ")
+		if err == io.EOF || err == liner.ErrPromptAborted {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		line.AppendHistory(input)
+		if input == "exit" {
+			return nil, nil
+		}
+
+		result, err := con.eval(input)
+		if err != nil {
+			fmt.Fprintf(c.stdout, "error: %v\n", err)
+			continue
+		}
+		if s, ok := result.(string); ok {
+			fmt.Fprintln(c.stdout, s)
+		} else if result != nil {
+			if err := printResult(c.stdout, result, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// eval evaluates a line and returns its result, which is nil for lines without a result.
+func (c *console) eval(line string) (interface{}, error) {
+	if m := assignmentRegexp.FindStringSubmatch(line); m != nil {
+		result, err := c.eval(m[2])
+		if err != nil {
+			return nil, err
+		}
+		value, err := toJSONValue(result)
+		if err != nil {
+			return nil, err
+		}
+		c.vars[m[1]] = value
+		return value, nil
+	}
+
+	args, err := splitArgs(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+	for i, arg := range args[1:] {
+		if args[i+1], err = c.expand(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	switch args[0] {
+	case "help":
+		return consoleHelp, nil
+	case "methods":
+		return rpcMethods(), nil
+	case "vars":
+		return c.vars, nil
+	case "load":
+		return c.load(args[1:])
+	case "account":
+		return c.account()
+	case "sign":
+		return c.sign(args[1:])
+	case "send":
+		return c.send(args[1:])
+	}
+	return c.call(args[0], args[1:])
+}
+
+// call calls an RPC method, converting the arguments to the types of its parameters.
+func (c *console) call(name string, args []string) (interface{}, error) {
+	method := reflect.ValueOf(c.z.RPC).MethodByName(name)
+	if !method.IsValid() || !decodableParams(method.Type()) {
+		return nil, fmt.Errorf("unknown method or command %s", name)
+	}
+	typ := method.Type()
+	if len(args) != typ.NumIn() {
+		return nil, fmt.Errorf("%s takes %d arguments", name, typ.NumIn())
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		value := reflect.New(typ.In(i))
+		var err error
+		switch typ.In(i).Kind() {
+		case reflect.String:
+			value.Elem().SetString(arg)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var n int64
+			n, err = strconv.ParseInt(arg, 10, 64)
+			value.Elem().SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var n uint64
+			n, err = strconv.ParseUint(arg, 10, 64)
+			value.Elem().SetUint(n)
+		default:
+			err = json.Unmarshal([]byte(arg), value.Interface())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d of %s: %v", i+1, name, err)
+		}
+		in[i] = value.Elem()
+	}
+
+	out := method.Call(in)
+	if len(out) == 0 {
+		return nil, nil
+	}
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		return nil, err
+	}
+	if len(out) == 1 {
+		return nil, nil
+	}
+	return out[0].Interface(), nil
+}
+
+func (c *console) load(args []string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, errors.New("usage: load FILE")
+	}
+	keystore, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := c.passphrase("passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := c.z.DecryptPrivateKey(keystore, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	c.privateKey = privateKey
+	return c.account()
+}
+
+func (c *console) account() (interface{}, error) {
+	if c.privateKey == "" {
+		return nil, errors.New("no key is loaded")
+	}
+	address, err := c.z.GetAddressFromPrivateKey(c.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	bech32, err := c.z.ToBech32Address(address)
+	if err != nil {
+		return nil, err
+	}
+	result := fields{{"address", address}, {"bech32Address", bech32}}
+	balance, err := c.z.RPC.GetBalance(address)
+	if err != nil {
+		return append(result, field{"balance", err.Error()}), nil
+	}
+	return append(result, field{"balance", balance.Balance}, field{"nonce", balance.Nonce}), nil
+}
+
+func (c *console) sign(args []string) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, errors.New("usage: sign TO AMOUNT")
+	}
+	if c.privateKey == "" {
+		return nil, errors.New("no key is loaded")
+	}
+	to, err := parseAddress(c.z, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return c.z.SignTransfer(c.privateKey, to, args[1], nil)
+}
+
+func (c *console) send(args []string) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, errors.New("usage: send TO AMOUNT")
+	}
+	if c.privateKey == "" {
+		return nil, errors.New("no key is loaded")
+	}
+	to, err := parseAddress(c.z, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return c.z.Transfer(context.Background(), c.privateKey, to, args[1], nil)
+}
+
+// expand replaces a $name.path argument by the value of a variable. Strings are inserted as they are,
+// and other values as JSON.
+func (c *console) expand(arg string) (string, error) {
+	if !strings.HasPrefix(arg, "$") {
+		return arg, nil
+	}
+	path := strings.Split(arg[1:], ".")
+	value, ok := c.vars[path[0]]
+	if !ok {
+		return "", fmt.Errorf("undefined variable %s", path[0])
+	}
+	for _, key := range path[1:] {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if value, ok = v[key]; !ok {
+				return "", fmt.Errorf("%s has no field %s", arg, key)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return "", fmt.Errorf("%s has no element %s", arg, key)
+			}
+			value = v[i]
+		default:
+			return "", fmt.Errorf("%s has no field %s", arg, key)
+		}
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// complete returns the completions of a line, which are method and command names for the first word
+// and variable names for words starting with $.
+func (c *console) complete(line string) []string {
+	start := strings.LastIndexAny(line, " \t") + 1
+	prefix, word := line[:start], line[start:]
+
+	var candidates []string
+	switch {
+	case strings.HasPrefix(word, "$"):
+		for name := range c.vars {
+			candidates = append(candidates, "$"+name)
+		}
+	case strings.TrimSpace(prefix) == "" || assignedRegexp.MatchString(prefix):
+		candidates = append(rpcMethods(), consoleCommands...)
+	}
+
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, prefix+candidate)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// rpcMethods returns the names of the methods of RPC which can be called from the console.
+func rpcMethods() []string {
+	value := reflect.ValueOf(&zillean.RPC{})
+	var methods []string
+	for i := 0; i < value.NumMethod(); i++ {
+		if decodableParams(value.Method(i).Type()) {
+			methods = append(methods, value.Type().Method(i).Name)
+		}
+	}
+	return methods
+}
+
+// decodableParams reports whether every parameter of a function can be read from a console argument.
+// Parameters such as a context.Context or an interface{} to decode into cannot.
+func decodableParams(typ reflect.Type) bool {
+	for i := 0; i < typ.NumIn(); i++ {
+		if !decodable(typ.In(i), map[reflect.Type]bool{}) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodable reports whether a value of typ can be decoded from JSON, which is not the case if it holds
+// an interface or a func or chan.
+func decodable(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[typ] {
+		return true
+	}
+	seen[typ] = true
+	switch typ.Kind() {
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return decodable(typ.Elem(), seen)
+	case reflect.Map:
+		return decodable(typ.Key(), seen) && decodable(typ.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if field := typ.Field(i); field.PkgPath == "" && !decodable(field.Type, seen) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitArgs splits a line into arguments separated by spaces, which may be quoted with ' or ".
+func splitArgs(line string) ([]string, error) {
+	var (
+		args  []string
+		arg   strings.Builder
+		inArg bool
+		quote rune
+	)
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// toJSONValue converts a result to the generic value of its JSON encoding, so that variables can be
+// indexed by field names.
+func toJSONValue(result interface{}) (interface{}, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GincoInc/zillean"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConsole_eval(t *testing.T) {
+	Convey("calls RPC methods with variables", t, func() {
+		node := newStubNode(nil)
+		defer node.Close()
+		con := newConsole(zillean.NewZillean(node.URL), nil)
+
+		result, err := con.eval("GetBalance " + testAddress)
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, &zillean.Balance{Balance: "1000", Nonce: 3})
+
+		result, err = con.eval("block = GetTxBlock 12345")
+		So(err, ShouldBeNil)
+		So(con.vars["block"], ShouldResemble, result)
+		result, err = con.eval("GetTransactionsForTxBlock $block.header.BlockNum")
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, [][]string{{"tx-12345"}})
+
+		result, err = con.eval(`hashes = GetTransactionsForTxBlock "7"`)
+		So(err, ShouldBeNil)
+		_, err = con.eval("GetTransaction $hashes.0.0")
+		So(err, ShouldBeNil)
+
+		_, err = con.eval("GetTxBlock $missing")
+		So(err.Error(), ShouldEqual, "undefined variable missing")
+		_, err = con.eval("GetTxBlock $block.body.nothing")
+		So(err.Error(), ShouldEqual, "$block.body.nothing has no field nothing")
+		_, err = con.eval("GetTxBlock")
+		So(err.Error(), ShouldEqual, "GetTxBlock takes 1 arguments")
+		_, err = con.eval("DSBlockListing abc")
+		So(err, ShouldNotBeNil)
+		_, err = con.eval("Unknown 1")
+		So(err.Error(), ShouldEqual, "unknown method or command Unknown")
+		_, err = con.eval("WaitForTransaction ctx abcd null")
+		So(err.Error(), ShouldEqual, "unknown method or command WaitForTransaction")
+
+		result, err = con.eval("methods")
+		So(err, ShouldBeNil)
+		So(result, ShouldContain, "GetTxBlock")
+		So(result, ShouldNotContain, "WaitForTransaction")
+		So(result, ShouldNotContain, "GetMapEntry")
+		So(result, ShouldNotContain, "GetMapEntries")
+	})
+
+	Convey("signs transfers with a key loaded from a keystore", t, func() {
+		node := newStubNode(nil)
+		defer node.Close()
+		z := zillean.NewZillean(node.URL)
+
+		dir, err := ioutil.TempDir("", "zillean")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		keystore, err := z.EncryptPrivateKey(testPrivateKey, "secret", zillean.KDFScrypt)
+		So(err, ShouldBeNil)
+		path := filepath.Join(dir, "key.json")
+		So(ioutil.WriteFile(path, keystore, 0600), ShouldBeNil)
+
+		con := newConsole(z, func(string) (string, error) { return "secret", nil })
+		_, err = con.eval("sign " + testAddress + " 100")
+		So(err.Error(), ShouldEqual, "no key is loaded")
+
+		result, err := con.eval("load " + path)
+		So(err, ShouldBeNil)
+		So(result.(fields)[0], ShouldResemble, field{"address", testAddress})
+
+		result, err = con.eval("sign zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf 100")
+		So(err, ShouldBeNil)
+		rawTx := result.(*zillean.RawTransaction)
+		So(rawTx.Version, ShouldEqual, 333<<16|1)
+		So(rawTx.Nonce, ShouldEqual, 4)
+		So(rawTx.GasPrice.String(), ShouldEqual, "2000000000")
+		So(rawTx.Signature, ShouldHaveLength, 128)
+
+		con = newConsole(z, func(string) (string, error) { return "wrong", nil })
+		_, err = con.eval("load " + path)
+		So(err.Error(), ShouldEqual, "wrong passphrase")
+	})
+}
+
+func TestConsole_complete(t *testing.T) {
+	Convey("completes method names and variables", t, func() {
+		con := newConsole(zillean.NewZillean(""), nil)
+		con.vars["block"] = map[string]interface{}{}
+		con.vars["balance"] = "1"
+
+		So(con.complete("GetTxB"), ShouldResemble, []string{"GetTxBlock", "GetTxBlockRate", "GetTxBlockVerbose"})
+		So(con.complete("b = GetLatestD"), ShouldResemble, []string{"b = GetLatestDsBlock"})
+		So(con.complete("he"), ShouldResemble, []string{"help"})
+		So(con.complete("GetTxBlock $b"), ShouldResemble, []string{"GetTxBlock $balance", "GetTxBlock $block"})
+		So(con.complete("GetTxBlock Get"), ShouldBeEmpty)
+	})
+}
+
+func TestSplitArgs(t *testing.T) {
+	Convey("splits arguments with quotes", t, func() {
+		args, err := splitArgs(`CreateTransaction '{"toAddr": "ab"}'  "a b" c`)
+		So(err, ShouldBeNil)
+		So(args, ShouldResemble, []string{"CreateTransaction", `{"toAddr": "ab"}`, "a b", "c"})
+
+		args, err = splitArgs(`GetTxBlock ""`)
+		So(err, ShouldBeNil)
+		So(args, ShouldResemble, []string{"GetTxBlock", ""})
+
+		_, err = splitArgs(`GetTxBlock "1`)
+		So(err.Error(), ShouldEqual, "unterminated quote")
+	})
+}
@@ -0,0 +1,387 @@
+// Command zillean performs key, address and transaction operations on the Zilliqa blockchain.
+//
+//	zillean keygen
+//	zillean address zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf
+//	zillean sign -private-key KEY -to ADDRESS -amount 1000000000000 -nonce 1 -gas-price 1000000000 > tx.json
+//	zillean -endpoint https://api.zilliqa.com broadcast tx.json
+//	zillean balance 0x1d19918a737306218b5cbb3241fcdcbd998c3a72
+//	zillean block latest
+//	zillean -json tx 8ecc34aaa6e3a5a27c24af5fcf9fbb25cb3e7a2b1d8e72d87a8f2fae9fd0df13
+//	zillean console
+//
+// Signing is done offline, so a signed transaction can be built on an air-gapped machine and broadcast from another.
+// The -json flag prints the results as JSON for scripting. The console calls any RPC method interactively,
+// with variables, tab completion, and transactions signed by a key loaded from a keystore.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/GincoInc/zillean"
+)
+
+const usage = `Usage: zillean [-endpoint URL] [-json] COMMAND [ARGS]
+
+Commands:
+  keygen                          generate a private key
+  address ADDRESS                 convert an address between hex, checksum and bech32
+  address -public-key KEY         derive the address of a public key
+  address -private-key KEY        derive the keys and address of a private key
+  sign [FLAGS]                    build and sign a transaction offline
+  broadcast [FILE]                send a signed transaction read from FILE or stdin
+  balance ADDRESS                 query the balance and nonce of an account
+  block [-ds] NUMBER|latest       query a TX block, or a DS block with -ds
+  tx TXID                         query a transaction
+  console                         start an interactive console
+
+Flags:
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "zillean: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("zillean", flag.ContinueOnError)
+	endpoint := flags.String("endpoint", "https://api.zilliqa.com", "JSON-RPC endpoint of a Zilliqa node")
+	jsonOut := flags.Bool("json", false, "print the results as JSON")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no command")
+	}
+
+	cli := &cli{z: zillean.NewZillean(*endpoint), stdin: stdin, stdout: stdout}
+	commands := map[string]func([]string) (interface{}, error){
+		"keygen":    cli.keygen,
+		"address":   cli.address,
+		"sign":      cli.sign,
+		"broadcast": cli.broadcast,
+		"balance":   cli.balance,
+		"block":     cli.block,
+		"tx":        cli.tx,
+		"console":   cli.console,
+	}
+	command, ok := commands[flags.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q", flags.Arg(0))
+	}
+	result, err := command(flags.Args()[1:])
+	if err != nil || result == nil {
+		return err
+	}
+	return printResult(stdout, result, *jsonOut)
+}
+
+type cli struct {
+	z      *zillean.Zillean
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+// field is a named value of a result which is printed as text.
+type field struct {
+	name  string
+	value interface{}
+}
+
+// fields is a result printed as aligned "name: value" lines, or as a JSON object keeping the order of the fields.
+type fields []field
+
+func (fs fields) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range fs {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.name)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// printResult writes a result. Results other than fields are always written as JSON.
+func printResult(w io.Writer, result interface{}, jsonOut bool) error {
+	if fs, ok := result.(fields); ok && !jsonOut {
+		width := 0
+		for _, f := range fs {
+			if len(f.name) > width {
+				width = len(f.name)
+			}
+		}
+		for _, f := range fs {
+			if _, err := fmt.Fprintf(w, "%-*s %v\n", width+1, f.name+":", f.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func (c *cli) keygen(args []string) (interface{}, error) {
+	flags := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return c.privateKeyFields(c.z.GeneratePrivateKey())
+}
+
+func (c *cli) address(args []string) (interface{}, error) {
+	flags := flag.NewFlagSet("address", flag.ContinueOnError)
+	publicKey := flags.String("public-key", "", "public key whose address is derived")
+	privateKey := flags.String("private-key", "", "private key whose keys and address are derived")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case *privateKey != "":
+		return c.privateKeyFields(strings.TrimPrefix(*privateKey, "0x"))
+	case *publicKey != "":
+		key := strings.TrimPrefix(*publicKey, "0x")
+		if !c.z.IsPublicKey(key) {
+			return nil, errors.New("invalid public key")
+		}
+		address, err := c.z.GetAddressFromPublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return c.addressFields(fields{{"publicKey", strings.ToLower(key)}}, address)
+	case flags.NArg() == 1:
+		address, err := parseAddress(c.z, flags.Arg(0))
+		if err != nil {
+			return nil, err
+		}
+		return c.addressFields(nil, address)
+	}
+	return nil, errors.New("usage: zillean address ADDRESS | -public-key KEY | -private-key KEY")
+}
+
+func (c *cli) sign(args []string) (interface{}, error) {
+	flags := flag.NewFlagSet("sign", flag.ContinueOnError)
+	privateKey := flags.String("private-key", "", "private key of the sender (required)")
+	to := flags.String("to", "", "recipient address, in hex or bech32 (required)")
+	amount := flags.String("amount", "0", "amount in Qa")
+	nonce := flags.Uint64("nonce", 0, "nonce of the transaction, which is the nonce of the sender account plus one (required)")
+	gasPrice := flags.String("gas-price", "", "gas price in Qa (required)")
+	gasLimit := flags.Uint64("gas-limit", 1, "gas limit")
+	chainID := flags.Uint("chain-id", 1, "chain ID of the network, which is 1 for the mainnet and 333 for the testnet")
+	data := flags.String("data", "", "contract call data")
+	code := flags.String("code", "", "file of the contract code to deploy")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	key := strings.TrimPrefix(*privateKey, "0x")
+	if key == "" || *to == "" || *nonce == 0 || *gasPrice == "" {
+		return nil, errors.New("-private-key, -to, -nonce and -gas-price are required")
+	}
+	if ok, err := c.z.VerifyPrivateKey(key); !ok {
+		return nil, err
+	}
+	toAddr, err := parseAddress(c.z, *to)
+	if err != nil {
+		return nil, err
+	}
+	// The signed encoding drops the sign of the amount and the gas price.
+	if n, ok := new(big.Int).SetString(*amount, 10); !ok || n.Sign() < 0 {
+		return nil, errors.New("invalid amount")
+	}
+	price, ok := new(big.Int).SetString(*gasPrice, 10)
+	if !ok || price.Sign() < 0 {
+		return nil, errors.New("invalid gas price")
+	}
+	if *chainID > 0xffff {
+		return nil, errors.New("invalid chain ID")
+	}
+	pubKey, err := c.z.GetPublicKeyFromPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx := zillean.RawTransaction{
+		// The message version is 1.
+		Version:  uint32(*chainID)<<16 | 1,
+		Nonce:    *nonce,
+		To:       toAddr,
+		Amount:   *amount,
+		PubKey:   pubKey,
+		GasPrice: price,
+		GasLimit: *gasLimit,
+		Data:     *data,
+	}
+	if *code != "" {
+		src, err := ioutil.ReadFile(*code)
+		if err != nil {
+			return nil, err
+		}
+		rawTx.Code = string(src)
+	}
+	rawTx.Signature, err = c.z.SignTransaction(rawTx, key)
+	if err != nil {
+		return nil, err
+	}
+	return rawTx, nil
+}
+
+func (c *cli) broadcast(args []string) (interface{}, error) {
+	flags := flag.NewFlagSet("broadcast", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if flags.NArg() == 0 || flags.Arg(0) == "-" {
+		data, err = ioutil.ReadAll(c.stdin)
+	} else {
+		data, err = ioutil.ReadFile(flags.Arg(0))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rawTx zillean.RawTransaction
+	if err := json.Unmarshal(data, &rawTx); err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %v", err)
+	}
+	if rawTx.Signature == "" {
+		return nil, errors.New("the transaction is not signed")
+	}
+	txID, err := c.z.RPC.CreateTransaction(rawTx, rawTx.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return fields{{"txID", txID}}, nil
+}
+
+func (c *cli) balance(args []string) (interface{}, error) {
+	flags := flag.NewFlagSet("balance", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, errors.New("usage: zillean balance ADDRESS")
+	}
+	address, err := parseAddress(c.z, flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	balance, err := c.z.RPC.GetBalance(address)
+	if err != nil {
+		return nil, err
+	}
+	return fields{{"address", address}, {"balance", balance.Balance}, {"nonce", balance.Nonce}}, nil
+}
+
+func (c *cli) block(args []string) (interface{}, error) {
+	flags := flag.NewFlagSet("block", flag.ContinueOnError)
+	ds := flags.Bool("ds", false, "query a DS block")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, errors.New("usage: zillean block [-ds] NUMBER|latest")
+	}
+	number := flags.Arg(0)
+	if number != "latest" {
+		if _, err := strconv.ParseUint(number, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid block number %s", number)
+		}
+	}
+
+	switch {
+	case *ds && number == "latest":
+		return c.z.RPC.GetLatestDsBlock()
+	case *ds:
+		return c.z.RPC.GetDsBlock(number)
+	case number == "latest":
+		return c.z.RPC.GetLatestTxBlock()
+	}
+	return c.z.RPC.GetTxBlock(number)
+}
+
+func (c *cli) tx(args []string) (interface{}, error) {
+	flags := flag.NewFlagSet("tx", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, errors.New("usage: zillean tx TXID")
+	}
+	return c.z.RPC.GetTransaction(strings.TrimPrefix(flags.Arg(0), "0x"))
+}
+
+// parseAddress returns the lowercase hex form of an address given in hex or bech32.
+func parseAddress(z *zillean.Zillean, address string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(address), "zil1") {
+		return z.FromBech32Address(address)
+	}
+	address = strings.TrimPrefix(address, "0x")
+	if !z.IsAddress(address) {
+		return "", fmt.Errorf("invalid address %s", address)
+	}
+	return strings.ToLower(address), nil
+}
+
+func (c *cli) privateKeyFields(privateKey string) (interface{}, error) {
+	if ok, err := c.z.VerifyPrivateKey(privateKey); !ok {
+		return nil, err
+	}
+	publicKey, err := c.z.GetPublicKeyFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	address, err := c.z.GetAddressFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return c.addressFields(fields{{"privateKey", privateKey}, {"publicKey", publicKey}}, address)
+}
+
+// addressFields appends the forms of an address to fs.
+func (c *cli) addressFields(fs fields, address string) (interface{}, error) {
+	checksum, err := c.z.ToChecksumAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	bech32, err := c.z.ToBech32Address(address)
+	if err != nil {
+		return nil, err
+	}
+	return append(fs, field{"address", address}, field{"checksumAddress", checksum}, field{"bech32Address", bech32}), nil
+}
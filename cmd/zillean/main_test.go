@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GincoInc/zillean"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	testPrivateKey = "b4eb8e8b343e2cce46db4e7571ec1d9654693cca200bc41cc20148355ca62ed9"
+	testPublicKey  = "0314738163b9bb67ad11aa464fe69a1147df263e8970d7dcfd8f993ddd39e81bd9"
+	testAddress    = "4baf5fada8e5db92c3d3242618c5b47133ae003c"
+)
+
+// newStubNode returns a JSON-RPC server answering a few methods, which passes the params of CreateTransaction
+// to created.
+func newStubNode(created chan<- json.RawMessage) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var result interface{}
+		switch req.Method {
+		case "GetBalance":
+			result = map[string]interface{}{"balance": "1000", "nonce": 3}
+		case "GetLatestTxBlock":
+			result = map[string]interface{}{"header": map[string]interface{}{"BlockNum": "42"}}
+		case "GetTxBlock":
+			var args []string
+			json.Unmarshal(req.Params, &args)
+			result = map[string]interface{}{"header": map[string]interface{}{"BlockNum": args[0], "NumTxns": 2}}
+		case "GetTransactionsForTxBlock":
+			var args []string
+			json.Unmarshal(req.Params, &args)
+			result = [][]string{{"tx-" + args[0]}}
+		case "GetNetworkId":
+			result = "333"
+		case "GetMinimumGasPrice":
+			result = "2000000000"
+		case "CreateTransaction":
+			created <- req.Params
+			result = map[string]interface{}{"Info": "Non-contract txn, sent to shard", "TranID": "abcd"}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": req.ID, "jsonrpc": "2.0", "result": result})
+	}))
+}
+
+func runCommand(stdin string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout)
+	return stdout.String(), err
+}
+
+func TestAddress(t *testing.T) {
+	Convey("converts addresses", t, func() {
+		out, err := runCommand("", "address", "zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf")
+		So(err, ShouldBeNil)
+		So(out, ShouldEqual, ""+
+			"address:         1d19918a737306218b5cbb3241fcdcbd998c3a72\n"+
+			"checksumAddress: 0x1d19918A737306218b5CBB3241FcdcBd998c3a72\n"+
+			"bech32Address:   zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf\n")
+
+		out, err = runCommand("", "-json", "address", "-private-key", testPrivateKey)
+		So(err, ShouldBeNil)
+		var result map[string]string
+		So(json.Unmarshal([]byte(out), &result), ShouldBeNil)
+		So(result["publicKey"], ShouldEqual, testPublicKey)
+		So(result["address"], ShouldEqual, testAddress)
+		So(result["checksumAddress"], ShouldEqual, "0x4BAF5faDA8e5Db92C3d3242618c5B47133AE003C")
+
+		_, err = runCommand("", "address", "0x1234")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestKeygen(t *testing.T) {
+	Convey("generates a private key with its address", t, func() {
+		out, err := runCommand("", "-json", "keygen")
+		So(err, ShouldBeNil)
+		var result map[string]string
+		So(json.Unmarshal([]byte(out), &result), ShouldBeNil)
+		z := zillean.NewZillean("")
+		address, err := z.GetAddressFromPrivateKey(result["privateKey"])
+		So(err, ShouldBeNil)
+		So(result["address"], ShouldEqual, address)
+		So(z.IsBech32Address(result["bech32Address"]), ShouldBeTrue)
+	})
+}
+
+func TestSignAndBroadcast(t *testing.T) {
+	Convey("signs a transaction offline and broadcasts it", t, func() {
+		signed, err := runCommand("", "sign", "-private-key", testPrivateKey, "-to", "zil1r5verznnwvrzrz6uhveyrlxuhkvccwnju4aehf",
+			"-amount", "1000000", "-nonce", "4", "-gas-price", "1000000000", "-chain-id", "333")
+		So(err, ShouldBeNil)
+		var rawTx zillean.RawTransaction
+		So(json.Unmarshal([]byte(signed), &rawTx), ShouldBeNil)
+		So(rawTx.Version, ShouldEqual, 333<<16|1)
+		So(rawTx.To, ShouldEqual, "1d19918a737306218b5cbb3241fcdcbd998c3a72")
+		So(rawTx.PubKey, ShouldEqual, testPublicKey)
+		So(rawTx.GasLimit, ShouldEqual, 1)
+		signature, err := hex.DecodeString(rawTx.Signature)
+		So(err, ShouldBeNil)
+		So(len(signature), ShouldEqual, 64)
+
+		created := make(chan json.RawMessage, 1)
+		node := newStubNode(created)
+		defer node.Close()
+		out, err := runCommand(signed, "-endpoint", node.URL, "broadcast")
+		So(err, ShouldBeNil)
+		So(out, ShouldEqual, "txID: abcd\n")
+		So(string(<-created), ShouldContainSubstring, rawTx.Signature)
+
+		_, err = runCommand("", "sign", "-private-key", testPrivateKey, "-to", testAddress)
+		So(err, ShouldNotBeNil)
+
+		for flags, expected := range map[[2]string]string{{"-1000", "1000000000"}: "invalid amount", {"1", "-1"}: "invalid gas price"} {
+			_, err = runCommand("", "sign", "-private-key", testPrivateKey, "-to", testAddress, "-nonce", "1", "-amount", flags[0], "-gas-price", flags[1])
+			So(err, ShouldBeError, expected)
+		}
+	})
+}
+
+func TestQueries(t *testing.T) {
+	Convey("queries balances and blocks", t, func() {
+		node := newStubNode(nil)
+		defer node.Close()
+
+		out, err := runCommand("", "-endpoint", node.URL, "balance", "0x"+testAddress)
+		So(err, ShouldBeNil)
+		So(out, ShouldEqual, "address: "+testAddress+"\nbalance: 1000\nnonce:   3\n")
+
+		out, err = runCommand("", "-endpoint", node.URL, "-json", "balance", "0x"+testAddress)
+		So(err, ShouldBeNil)
+		So(out, ShouldEqual, "{\n  \"address\": \""+testAddress+"\",\n  \"balance\": \"1000\",\n  \"nonce\": 3\n}\n")
+
+		out, err = runCommand("", "-endpoint", node.URL, "block", "latest")
+		So(err, ShouldBeNil)
+		var block zillean.TxBlock
+		So(json.Unmarshal([]byte(out), &block), ShouldBeNil)
+		So(block.Header.BlockNum, ShouldEqual, zillean.Number(42))
+
+		_, err = runCommand("", "-endpoint", node.URL, "block", "abc")
+		So(err, ShouldNotBeNil)
+		_, err = runCommand("", "unknown")
+		So(err.Error(), ShouldEqual, `unknown command "unknown"`)
+	})
+}
@@ -0,0 +1,181 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultContractGasLimit is the gas limit of a smart contract transaction.
+	defaultContractGasLimit = 10000
+	// zeroAddress is the recipient of contract deployment transactions.
+	zeroAddress = "0000000000000000000000000000000000000000"
+)
+
+var scillaVersionPattern = regexp.MustCompile(`^\s*scilla_version\s+(\d+)`)
+
+// ContractResult describes the outcome of a smart contract transaction.
+type ContractResult struct {
+	TxID     string
+	GasUsed  uint64
+	Success  bool
+	EpochNum uint64
+	Receipt  TransactionReceipt
+}
+
+// DeployResult describes the outcome of a smart contract deployment.
+type DeployResult struct {
+	ContractResult
+	ContractAddress string
+}
+
+// DeployContract deploys a Scilla contract with init parameters from the account of a private key,
+// waits for the confirmation and returns the address of the new contract.
+// The _scilla_version parameter is added from the code unless given in init. opts may be nil.
+// If the transaction is submitted but not confirmed or fails, the result holds its TxID along with the error.
+func (z *Zillean) DeployContract(ctx context.Context, privateKey, code string, init []Param, opts *TxOptions) (*DeployResult, error) {
+	scillaVersion, err := parseScillaVersion(code)
+	if err != nil {
+		return nil, err
+	}
+
+	params := init
+	if !hasParam(init, "_scilla_version") {
+		params = append([]Param{{"_scilla_version", Uint32(scillaVersion)}}, init...)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx := RawTransaction{
+		To:     zeroAddress,
+		Amount: "0",
+		Code:   code,
+		Data:   string(data),
+	}
+	txID, result, err := z.sendTransaction(ctx, rawTx, privateKey, defaultContractGasLimit, opts)
+	deployResult := &DeployResult{ContractResult: newContractResult(txID, result)}
+	if err != nil {
+		if txID != "" {
+			return deployResult, err
+		}
+		return nil, err
+	}
+	if !result.Success {
+		return deployResult, errors.New("contract deployment failed")
+	}
+
+	address, err := z.RPC.GetContractAddressFromTransactionID(txID)
+	if err != nil {
+		return deployResult, err
+	}
+	deployResult.ContractAddress = address
+	return deployResult, nil
+}
+
+// CallContract calls a transition of a Scilla contract with params, sending amount (in Qa) from the account
+// of a private key, and waits for the confirmation. opts may be nil.
+// If the transaction is submitted but not confirmed, the result holds its TxID along with the error.
+// A confirmed call which failed in the contract is not an error; see Success and Receipt in the result.
+func (z *Zillean) CallContract(ctx context.Context, privateKey, address, transition string, params []Param, amount string, opts *TxOptions) (*ContractResult, error) {
+	address = strings.TrimPrefix(address, "0x")
+	if !z.IsAddress(address) {
+		return nil, errors.New("invalid address")
+	}
+	if amount == "" {
+		amount = "0"
+	}
+	if err := checkAmount(amount); err != nil {
+		return nil, err
+	}
+	data, err := CallData(transition, params)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx := RawTransaction{
+		To:     address,
+		Amount: amount,
+		Data:   data,
+	}
+	txID, result, err := z.sendTransaction(ctx, rawTx, privateKey, defaultContractGasLimit, opts)
+	contractResult := newContractResult(txID, result)
+	if err != nil {
+		if txID != "" {
+			return &contractResult, err
+		}
+		return nil, err
+	}
+	return &contractResult, nil
+}
+
+// CallData returns the Scilla message calling a transition with params, which can be used as RawTransaction.Data.
+func CallData(transition string, params []Param) (string, error) {
+	if transition == "" {
+		return "", errors.New("missing transition name")
+	}
+	vnames := map[string]bool{}
+	for _, param := range params {
+		if vnames[param.Vname] {
+			return "", fmt.Errorf("duplicate param %s", param.Vname)
+		}
+		vnames[param.Vname] = true
+	}
+	if params == nil {
+		params = []Param{}
+	}
+
+	data, err := json.Marshal(struct {
+		Tag    string  `json:"_tag"`
+		Params []Param `json:"params"`
+	}{transition, params})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func newContractResult(txID string, result *TransactionResult) ContractResult {
+	if result == nil {
+		return ContractResult{TxID: txID}
+	}
+	gasUsed, _ := strconv.ParseUint(result.Transaction.Receipt.CumulativeGas, 10, 64)
+	return ContractResult{
+		TxID:     txID,
+		GasUsed:  gasUsed,
+		Success:  result.Success,
+		EpochNum: result.EpochNum,
+		Receipt:  result.Transaction.Receipt,
+	}
+}
+
+// parseScillaVersion returns the version in the scilla_version header of a contract, which defaults to 0.
+func parseScillaVersion(code string) (uint32, error) {
+	if strings.TrimSpace(code) == "" {
+		return 0, errors.New("empty contract code")
+	}
+	match := scillaVersionPattern.FindStringSubmatch(code)
+	if match == nil {
+		return 0, nil
+	}
+	version, err := strconv.ParseUint(match[1], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scilla_version: %v", err)
+	}
+	return uint32(version), nil
+}
+
+func hasParam(params []Param, vname string) bool {
+	for _, param := range params {
+		if param.Vname == vname {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const helloWorldCode = `scilla_version 0
+
+contract HelloWorld
+(owner: ByStr20)
+
+field welcome_msg : String = ""
+
+transition setHello (msg : String)
+  welcome_msg := msg
+end
+`
+
+func TestZillean_DeployContract(t *testing.T) {
+	Convey("deploys the contract and returns its address", t, func() {
+		var rawTx RawTransaction
+		node := newStubNode(newWalletNode(0, func(tx RawTransaction) { rawTx = tx }))
+		defer node.Close()
+
+		init := []Param{{"owner", ByStr20("0xf49f1306bc8fb0cd8167a58a3550c1443072e96b")}}
+		result, err := NewZillean(node.URL).DeployContract(context.Background(), testVectors[0].privateKey, helloWorldCode, init, &TxOptions{Wait: fastWait})
+		So(err, ShouldBeNil)
+		So(result.ContractAddress, ShouldEqual, "6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		So(result.TxID, ShouldEqual, "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5")
+		So(result.Success, ShouldBeTrue)
+
+		So(rawTx.To, ShouldEqual, "0000000000000000000000000000000000000000")
+		So(rawTx.Amount, ShouldEqual, "0")
+		So(rawTx.Nonce, ShouldEqual, 1)
+		So(rawTx.GasLimit, ShouldEqual, 10000)
+		So(rawTx.Code, ShouldEqual, helloWorldCode)
+		var data []map[string]string
+		So(json.Unmarshal([]byte(rawTx.Data), &data), ShouldBeNil)
+		So(data, ShouldResemble, []map[string]string{
+			{"vname": "_scilla_version", "type": "Uint32", "value": "0"},
+			{"vname": "owner", "type": "ByStr20", "value": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},
+		})
+	})
+
+	Convey("returns an error when the code is empty", t, func() {
+		_, err := NewZillean(localNet).DeployContract(context.Background(), testVectors[0].privateKey, "", nil, nil)
+		So(err.Error(), ShouldEqual, "empty contract code")
+	})
+}
+
+func TestParseScillaVersion(t *testing.T) {
+	Convey("returns the version in the scilla_version header", t, func() {
+		version, err := parseScillaVersion("\n  scilla_version 1\n\ncontract A ()")
+		So(err, ShouldBeNil)
+		So(version, ShouldEqual, 1)
+		version, err = parseScillaVersion("contract A ()")
+		So(err, ShouldBeNil)
+		So(version, ShouldEqual, 0)
+	})
+}
+
+func TestZillean_CallContract(t *testing.T) {
+	Convey("calls the transition and waits for the confirmation", t, func() {
+		var rawTx RawTransaction
+		node := newStubNode(newWalletNode(7, func(tx RawTransaction) { rawTx = tx }))
+		defer node.Close()
+
+		params := []Param{{"msg", String("Hello World")}}
+		result, err := NewZillean(node.URL).CallContract(context.Background(), testVectors[0].privateKey, "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "setHello", params, "", &TxOptions{Wait: fastWait})
+		So(err, ShouldBeNil)
+		So(result.TxID, ShouldEqual, "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5")
+		So(result.Success, ShouldBeTrue)
+		So(result.GasUsed, ShouldEqual, 1)
+
+		So(rawTx.To, ShouldEqual, "6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		So(rawTx.Amount, ShouldEqual, "0")
+		So(rawTx.Nonce, ShouldEqual, 8)
+		So(rawTx.GasLimit, ShouldEqual, 10000)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"setHello","params":[{"vname":"msg","type":"String","value":"Hello World"}]}`)
+	})
+
+	Convey("returns an error when a param is invalid", t, func() {
+		params := []Param{{"amount", Uint32(1)}, {"to", ByStr20("invalid")}}
+		_, err := NewZillean(localNet).CallContract(context.Background(), testVectors[0].privateKey, "6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "Transfer", params, "0", nil)
+		So(err.Error(), ShouldContainSubstring, "invalid value of to")
+	})
+
+	Convey("returns an error when the amount is negative", t, func() {
+		_, err := NewZillean(localNet).CallContract(context.Background(), testVectors[0].privateKey, "6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "getHello", nil, "-1000", nil)
+		So(err.Error(), ShouldEqual, "negative amount")
+	})
+}
+
+func TestCallData(t *testing.T) {
+	Convey("returns the Scilla message of a transition call", t, func() {
+		data, err := CallData("getHello", nil)
+		So(err, ShouldBeNil)
+		So(data, ShouldEqual, `{"_tag":"getHello","params":[]}`)
+
+		data, err = CallData("Transfer", []Param{
+			{"to", ByStr20("4baf5fada8e5db92c3d3242618c5b47133ae003c")},
+			{"amount", Uint128(big.NewInt(1000000))},
+		})
+		So(err, ShouldBeNil)
+		So(data, ShouldEqual, `{"_tag":"Transfer","params":[{"vname":"to","type":"ByStr20","value":"0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},{"vname":"amount","type":"Uint128","value":"1000000"}]}`)
+	})
+
+	Convey("returns an error when a param is duplicated", t, func() {
+		_, err := CallData("setHello", []Param{{"msg", String("a")}, {"msg", String("b")}})
+		So(err.Error(), ShouldEqual, "duplicate param msg")
+	})
+}
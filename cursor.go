@@ -0,0 +1,267 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Checkpoint identifies the last TX block processed by a BlockCursor.
+type Checkpoint struct {
+	BlockNum  uint64 `json:"blockNum"`
+	BlockHash string `json:"blockHash"`
+}
+
+// CheckpointStore persists the checkpoint of a BlockCursor.
+type CheckpointStore interface {
+	// Load returns the saved checkpoint, or nil if none has been saved.
+	Load() (*Checkpoint, error)
+	// Save replaces the saved checkpoint.
+	Save(checkpoint Checkpoint) error
+}
+
+// MemoryCheckpointStore is a CheckpointStore which keeps the checkpoint in memory.
+type MemoryCheckpointStore struct {
+	mu         sync.Mutex
+	checkpoint *Checkpoint
+}
+
+// Load implements CheckpointStore.
+func (s *MemoryCheckpointStore) Load() (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.checkpoint == nil {
+		return nil, nil
+	}
+	checkpoint := *s.checkpoint
+	return &checkpoint, nil
+}
+
+// Save implements CheckpointStore.
+func (s *MemoryCheckpointStore) Save(checkpoint Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoint = &checkpoint
+	return nil
+}
+
+// FileCheckpointStore is a CheckpointStore which keeps the checkpoint in a JSON file.
+// The file is replaced atomically, so it always holds either the old or the new checkpoint.
+type FileCheckpointStore struct {
+	Path string
+}
+
+// NewFileCheckpointStore returns a new FileCheckpointStore which keeps the checkpoint at path.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{Path: path}
+}
+
+// Load implements CheckpointStore.
+func (s *FileCheckpointStore) Load() (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %v", s.Path, err)
+	}
+	return &checkpoint, nil
+}
+
+// Save implements CheckpointStore. The checkpoint is synced to the disk before Save returns.
+func (s *FileCheckpointStore) Save(checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), s.Path)
+}
+
+// ChainInconsistencyError is returned by a BlockCursor when the node serves a TX block which does not
+// link to the checkpoint, which happens when the node is stale or serves a different chain.
+type ChainInconsistencyError struct {
+	BlockNum uint64
+	// Expected is the hash of the checkpoint block and Actual the PrevBlockHash of the block after it.
+	Expected string
+	Actual   string
+}
+
+func (e *ChainInconsistencyError) Error() string {
+	return fmt.Sprintf("block %d does not link to the checkpoint: PrevBlockHash is %s, expected %s", e.BlockNum, e.Actual, e.Expected)
+}
+
+// BlockCursor walks TX blocks in order for consumers which must process every block exactly once, such as deposit
+// processing. It returns a block only once Confirmations blocks have been built on top of it, verifies that the
+// PrevBlockHash of each block is the hash of the block before it, and persists the last processed block in Store.
+//
+// Call Next to get the next block, process it, then call Commit with it. After a restart, Next returns the block
+// after the last committed one, so a block which was being processed when the process stopped is returned again.
+type BlockCursor struct {
+	RPC   *RPC
+	Store CheckpointStore
+	// Start is the number of the first block to return if Store has no checkpoint.
+	Start uint64
+	// Confirmations is the number of blocks which must follow a block before it is returned.
+	Confirmations uint64
+	// Interval is the polling interval while waiting for confirmations. It defaults to 10s.
+	Interval time.Duration
+	// VerifyHashes makes Next and Commit check that the BlockHash of each block is the hash of its header,
+	// which fails for blocks whose header encoding differs from the one of TxBlock.HeaderBytes.
+	VerifyHashes bool
+
+	mu         sync.Mutex
+	loaded     bool
+	checkpoint *Checkpoint
+}
+
+// NewBlockCursor returns a new BlockCursor which persists its checkpoint in store.
+func NewBlockCursor(rpc *RPC, store CheckpointStore, confirmations uint64) *BlockCursor {
+	return &BlockCursor{RPC: rpc, Store: store, Confirmations: confirmations, Interval: 10 * time.Second}
+}
+
+// Checkpoint returns the last committed block, or nil if no block has been committed.
+func (c *BlockCursor) Checkpoint() (*Checkpoint, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	if c.checkpoint == nil {
+		return nil, nil
+	}
+	checkpoint := *c.checkpoint
+	return &checkpoint, nil
+}
+
+// Next waits until the block after the checkpoint has enough confirmations and returns it.
+// It returns a *ChainInconsistencyError if the block does not link to the checkpoint, and with VerifyHashes
+// an error if the BlockHash of the block is not the hash of its header.
+func (c *BlockCursor) Next(ctx context.Context) (*TxBlock, error) {
+	checkpoint, err := c.Checkpoint()
+	if err != nil {
+		return nil, err
+	}
+	next := c.Start
+	if checkpoint != nil {
+		next = checkpoint.BlockNum + 1
+	}
+
+	interval := c.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	for {
+		num, err := c.RPC.GetNumTxBlocks()
+		if err != nil {
+			return nil, err
+		}
+		count, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of TX blocks %s", num)
+		}
+		if count > next+c.Confirmations {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+
+	block, err := c.RPC.GetTxBlock(strconv.FormatUint(next, 10))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(block.Header.BlockNum) != next {
+		return nil, fmt.Errorf("node returned block %d instead of %d", block.Header.BlockNum, next)
+	}
+	if len(block.Body.BlockHash) == 0 {
+		return nil, fmt.Errorf("block %d has no BlockHash", next)
+	}
+	if c.VerifyHashes {
+		if err := block.VerifyHash(); err != nil {
+			return nil, err
+		}
+	}
+	if checkpoint != nil && !strings.EqualFold(block.Header.PrevBlockHash.String(), checkpoint.BlockHash) {
+		return nil, &ChainInconsistencyError{BlockNum: next, Expected: checkpoint.BlockHash, Actual: block.Header.PrevBlockHash.String()}
+	}
+	return block, nil
+}
+
+// Commit records block, which must be the block last returned by Next, as processed and saves the checkpoint.
+// With VerifyHashes, the BlockHash of the block is checked again, since it links the next block to the checkpoint.
+func (c *BlockCursor) Commit(block *TxBlock) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return err
+	}
+	blockNum := uint64(block.Header.BlockNum)
+	next := c.Start
+	if c.checkpoint != nil {
+		next = c.checkpoint.BlockNum + 1
+	}
+	if blockNum != next {
+		return fmt.Errorf("cannot commit block %d, the next block is %d", blockNum, next)
+	}
+	if c.checkpoint != nil && !strings.EqualFold(block.Header.PrevBlockHash.String(), c.checkpoint.BlockHash) {
+		return &ChainInconsistencyError{BlockNum: blockNum, Expected: c.checkpoint.BlockHash, Actual: block.Header.PrevBlockHash.String()}
+	}
+	if len(block.Body.BlockHash) == 0 {
+		return fmt.Errorf("block %d has no BlockHash", blockNum)
+	}
+	if c.VerifyHashes {
+		if err := block.VerifyHash(); err != nil {
+			return err
+		}
+	}
+
+	checkpoint := Checkpoint{BlockNum: blockNum, BlockHash: block.Body.BlockHash.String()}
+	if err := c.Store.Save(checkpoint); err != nil {
+		return err
+	}
+	c.checkpoint = &checkpoint
+	return nil
+}
+
+// load loads the checkpoint from Store once. c.mu must be held.
+func (c *BlockCursor) load() error {
+	if c.loaded {
+		return nil
+	}
+	checkpoint, err := c.Store.Load()
+	if err != nil {
+		return err
+	}
+	c.checkpoint, c.loaded = checkpoint, true
+	return nil
+}
@@ -0,0 +1,209 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testChain is a node stand-in serving a chain of TX blocks whose state root hashes are derived from fork,
+// so changing fork makes the node serve a different chain.
+type testChain struct {
+	mu     sync.Mutex
+	blocks uint64
+	fork   map[uint64]string
+}
+
+// block returns block n of the chain, with its BlockHash set to the hash of its header.
+func (c *testChain) block(n uint64) *TxBlock {
+	suffix := c.fork[n]
+	if suffix == "" {
+		suffix = "00"
+	}
+	block := newTestTxBlock()
+	block.Header.BlockNum = Number(n)
+	block.Header.StateRootHash = testHash(fmt.Sprintf("%062x%s", n, suffix))
+	if n > 0 {
+		block.Header.PrevBlockHash = testHash(c.hash(n - 1))
+	}
+	hash, _ := block.Hash()
+	block.Body.BlockHash = testHash(hash)
+	return block
+}
+
+func (c *testChain) hash(n uint64) string {
+	hash, _ := c.block(n).Hash()
+	return hash
+}
+
+func (c *testChain) handle(method string, params json.RawMessage) (interface{}, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch method {
+	case "GetNumTxBlocks":
+		return strconv.FormatUint(c.blocks, 10), ""
+	case "GetTxBlock":
+		var args []string
+		json.Unmarshal(params, &args)
+		n, _ := strconv.ParseUint(args[0], 10, 64)
+		return c.block(n), ""
+	}
+	return nil, "METHOD_NOT_FOUND: The method being requested is not available on this server"
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	Convey("saves and loads the checkpoint", t, func() {
+		dir, err := ioutil.TempDir("", "zillean")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		store := NewFileCheckpointStore(filepath.Join(dir, "checkpoint.json"))
+
+		checkpoint, err := store.Load()
+		So(err, ShouldBeNil)
+		So(checkpoint, ShouldBeNil)
+
+		So(store.Save(Checkpoint{BlockNum: 5, BlockHash: "ab"}), ShouldBeNil)
+		So(store.Save(Checkpoint{BlockNum: 6, BlockHash: "cd"}), ShouldBeNil)
+		checkpoint, err = store.Load()
+		So(err, ShouldBeNil)
+		So(*checkpoint, ShouldResemble, Checkpoint{BlockNum: 6, BlockHash: "cd"})
+
+		files, _ := ioutil.ReadDir(dir)
+		So(len(files), ShouldEqual, 1)
+	})
+}
+
+func TestBlockCursor(t *testing.T) {
+	Convey("returns confirmed blocks in order and resumes after the checkpoint", t, func() {
+		chain := &testChain{blocks: 5, fork: map[uint64]string{}}
+		node := newStubNode(chain.handle)
+		defer node.Close()
+
+		dir, err := ioutil.TempDir("", "zillean")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "checkpoint.json")
+
+		cursor := NewBlockCursor(NewRPC(node.URL), NewFileCheckpointStore(path), 2)
+		cursor.Start = 1
+		cursor.Interval = time.Millisecond
+		for n := 1; n <= 2; n++ {
+			block, err := cursor.Next(context.Background())
+			So(err, ShouldBeNil)
+			So(block.Header.BlockNum, ShouldEqual, Number(n))
+			So(cursor.Commit(block), ShouldBeNil)
+		}
+
+		// Block 3 needs blocks 4 and 5.
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		waited := make(chan error)
+		go func() {
+			_, err := cursor.Next(ctx)
+			waited <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		checkpoint, err := cursor.Checkpoint()
+		So(err, ShouldBeNil)
+		So(checkpoint.BlockNum, ShouldEqual, 2)
+		select {
+		case <-waited:
+			So("Next returned", ShouldBeEmpty)
+		default:
+		}
+		So(<-waited, ShouldResemble, context.DeadlineExceeded)
+		cancel()
+
+		chain.mu.Lock()
+		chain.blocks = 6
+		chain.mu.Unlock()
+		restarted := NewBlockCursor(NewRPC(node.URL), NewFileCheckpointStore(path), 2)
+		checkpoint, err = restarted.Checkpoint()
+		So(err, ShouldBeNil)
+		So(*checkpoint, ShouldResemble, Checkpoint{BlockNum: 2, BlockHash: chain.hash(2)})
+		block, err := restarted.Next(context.Background())
+		So(err, ShouldBeNil)
+		So(block.Header.BlockNum, ShouldEqual, Number(3))
+	})
+
+	Convey("detects blocks which do not link to the checkpoint", t, func() {
+		chain := &testChain{blocks: 10, fork: map[uint64]string{}}
+		node := newStubNode(chain.handle)
+		defer node.Close()
+
+		cursor := NewBlockCursor(NewRPC(node.URL), &MemoryCheckpointStore{}, 0)
+		block, err := cursor.Next(context.Background())
+		So(err, ShouldBeNil)
+		So(cursor.Commit(block), ShouldBeNil)
+		stale, err := cursor.Next(context.Background())
+		So(err, ShouldBeNil)
+
+		chain.mu.Lock()
+		chain.fork[0] = "ff"
+		chain.mu.Unlock()
+		forked := chain.hash(0)
+		cursor = NewBlockCursor(NewRPC(node.URL), &MemoryCheckpointStore{}, 0)
+		block, err = cursor.Next(context.Background())
+		So(err, ShouldBeNil)
+		So(cursor.Commit(block), ShouldBeNil)
+		So(cursor.Commit(stale), ShouldHaveSameTypeAs, &ChainInconsistencyError{})
+
+		chain.mu.Lock()
+		chain.fork[0] = ""
+		chain.mu.Unlock()
+		_, err = cursor.Next(context.Background())
+		So(err, ShouldResemble, &ChainInconsistencyError{BlockNum: 1, Expected: forked, Actual: chain.hash(0)})
+
+		checkpoint, err := cursor.Checkpoint()
+		So(err, ShouldBeNil)
+		So(checkpoint.BlockNum, ShouldEqual, 0)
+	})
+
+	Convey("rejects commits out of order", t, func() {
+		chain := &testChain{blocks: 10, fork: map[uint64]string{}}
+		node := newStubNode(chain.handle)
+		defer node.Close()
+
+		cursor := NewBlockCursor(NewRPC(node.URL), &MemoryCheckpointStore{}, 0)
+		block, err := cursor.Next(context.Background())
+		So(err, ShouldBeNil)
+		So(cursor.Commit(block), ShouldBeNil)
+		So(cursor.Commit(block).Error(), ShouldEqual, "cannot commit block 0, the next block is 1")
+	})
+
+	Convey("rejects blocks whose BlockHash is not the hash of their header with VerifyHashes", t, func() {
+		chain := &testChain{blocks: 10, fork: map[uint64]string{}}
+		node := newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+			result, msg := chain.handle(method, params)
+			if block, ok := result.(*TxBlock); ok {
+				block.Body.BlockHash = testHash(strings.Repeat("ab", 32))
+			}
+			return result, msg
+		})
+		defer node.Close()
+
+		cursor := NewBlockCursor(NewRPC(node.URL), &MemoryCheckpointStore{}, 0)
+		_, err := cursor.Next(context.Background())
+		So(err, ShouldBeNil)
+
+		cursor.VerifyHashes = true
+		_, err = cursor.Next(context.Background())
+		So(err.Error(), ShouldStartWith, "block 0 has BlockHash "+strings.Repeat("ab", 32))
+
+		block := chain.block(0)
+		block.Header.GasUsed = 1
+		So(cursor.Commit(block).Error(), ShouldStartWith, "block 0 has BlockHash "+chain.hash(0))
+		checkpoint, err := cursor.Checkpoint()
+		So(err, ShouldBeNil)
+		So(checkpoint, ShouldBeNil)
+	})
+}
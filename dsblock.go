@@ -0,0 +1,140 @@
+package zillean
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ParsedDsBlock is a DS block with its fields parsed from the strings returned by the node.
+type ParsedDsBlock struct {
+	BlockNum *big.Int
+	// EpochNum is nil if the node does not return it.
+	EpochNum     *big.Int
+	Timestamp    *big.Int
+	GasPrice     *big.Int
+	Difficulty   uint32
+	DifficultyDS uint32
+	// LeaderPubKey and PoWWinners are compressed public keys of 33 bytes.
+	LeaderPubKey []byte
+	PoWWinners   [][]byte
+	// CommitteeHash and ShardingHash are nil if the node does not return them.
+	CommitteeHash []byte
+	ShardingHash  []byte
+	Prevhash      []byte
+	// Signature is the co-signature (r, s) of 64 bytes.
+	Signature []byte
+	Version   uint32
+}
+
+// Parse returns the fields of the block parsed into typed values.
+//
+// Nodes leave parts of the header out of the block they return, such as the software info and the peers of
+// the PoW winners, so the hash of a DS block cannot be computed from it. VerifyDsBlocks takes the hashes from
+// DSBlockListing instead.
+func (b *DsBlock) Parse() (*ParsedDsBlock, error) {
+	h := b.Header
+	var p ParsedDsBlock
+	var err error
+	p.BlockNum = new(big.Int).SetUint64(uint64(h.BlockNum))
+	if h.EpochNum != nil {
+		p.EpochNum = new(big.Int).SetUint64(uint64(*h.EpochNum))
+	}
+	p.Timestamp = new(big.Int).SetUint64(h.Timestamp.Micros())
+	p.GasPrice = new(big.Int).Set(h.GasPrice.Int())
+	if p.GasPrice.Sign() < 0 {
+		return nil, fmt.Errorf("invalid gasPrice %s", h.GasPrice)
+	}
+	if h.Difficulty < 0 || h.Difficulty > 0xff || h.DifficultyDS < 0 || h.DifficultyDS > 0xff {
+		return nil, fmt.Errorf("invalid difficulty %d or difficultyDS %d", h.Difficulty, h.DifficultyDS)
+	}
+	p.Difficulty, p.DifficultyDS = uint32(h.Difficulty), uint32(h.DifficultyDS)
+	if h.Version < 0 || h.Version > 0xffffffff {
+		return nil, fmt.Errorf("invalid version %d", h.Version)
+	}
+	p.Version = uint32(h.Version)
+
+	if p.LeaderPubKey, err = parsePublicKey(h.LeaderPubKey); err != nil {
+		return nil, err
+	}
+	for _, winner := range h.PoWWinners {
+		pubKey, err := parsePublicKey(winner)
+		if err != nil {
+			return nil, err
+		}
+		p.PoWWinners = append(p.PoWWinners, pubKey)
+	}
+	if h.CommitteeHash != nil {
+		if p.CommitteeHash, err = hashBytes("committeeHash", h.CommitteeHash); err != nil {
+			return nil, err
+		}
+	}
+	if h.ShardingHash != nil {
+		if p.ShardingHash, err = hashBytes("shardingHash", h.ShardingHash); err != nil {
+			return nil, err
+		}
+	}
+	if p.Prevhash, err = hashBytes("prevhash", h.Prevhash); err != nil {
+		return nil, err
+	}
+	if p.Signature, err = parseSignature("signature", b.Signature); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// VerifyDsBlocks returns the DS blocks from from to to, checking that the prevhash of each block after the first
+// is the hash of the block before it as listed by DSBlockListing. The listed hashes are taken from the node as
+// they are, since the hash of a DS block cannot be computed from the block the node returns.
+func VerifyDsBlocks(ctx context.Context, rpc *RPC, from, to uint64) ([]*DsBlock, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid range %d to %d", from, to)
+	}
+	it := NewBlockIterator(rpc.DSBlockListing, OldestFirst)
+	it.StartAt, it.StopAt = &from, &to
+	var blocks []*DsBlock
+	var prevHash Hash
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		listed := it.Block()
+		blockNum := uint64(listed.BlockNum)
+		block, err := rpc.GetDsBlock(strconv.FormatUint(blockNum, 10))
+		if err != nil {
+			return nil, fmt.Errorf("DS block %d: %v", blockNum, err)
+		}
+		if uint64(block.Header.BlockNum) != blockNum {
+			return nil, fmt.Errorf("node returned DS block %d for %d", block.Header.BlockNum, blockNum)
+		}
+		if blockNum > from && !bytes.Equal(block.Header.Prevhash, prevHash) {
+			return nil, fmt.Errorf("DS block %d does not link to DS block %d: prevhash is %s, expected %s",
+				blockNum, blockNum-1, block.Header.Prevhash, prevHash)
+		}
+		prevHash = listed.Hash
+		blocks = append(blocks, block)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	if uint64(len(blocks)) != to-from+1 {
+		return nil, fmt.Errorf("DS block listing ends before DS block %d", to)
+	}
+	return blocks, nil
+}
+
+// parsePublicKey returns the bytes of a compressed public key, with or without the 0x prefix.
+func parsePublicKey(value string) ([]byte, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(value), "0x"))
+	if err != nil || len(pubKey) != 33 {
+		return nil, fmt.Errorf("invalid public key %s", value)
+	}
+	if x, _ := NewECSchnorr().unmarshalPublicKey(pubKey); x == nil {
+		return nil, fmt.Errorf("invalid public key %s", value)
+	}
+	return pubKey, nil
+}
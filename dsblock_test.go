@@ -0,0 +1,143 @@
+package zillean
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestDsBlock(blockNum uint64, prevhash string) *DsBlock {
+	var block DsBlock
+	block.Header.BlockNum = Number(blockNum)
+	block.Header.Difficulty = 3
+	block.Header.DifficultyDS = 5
+	block.Header.GasPrice.Int().SetInt64(1000000000)
+	block.Header.LeaderPubKey = "0x" + strings.ToUpper(testVectors[0].publicKey)
+	block.Header.PoWWinners = []string{"0x" + testVectors[1].publicKey}
+	block.Header.Prevhash = testHash(prevhash)
+	block.Header.Timestamp = Timestamp{time.Unix(1549265830, 654931000).UTC()}
+	block.Signature = strings.Repeat("CF", 64)
+	return &block
+}
+
+// newDsChainNode returns a node stand-in serving DS blocks from 0 to newest and their listing. The listed hash
+// of block n is dsHash(n), and the prevhash of block n is prevhash(n).
+func newDsChainNode(newest int64, prevhash func(n int64) string) func(method string, params json.RawMessage) (interface{}, string) {
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		switch method {
+		case "DSBlockListing":
+			var args []int64
+			json.Unmarshal(params, &args)
+			result := &ListedBlocks{MaxPages: (newest + 10) / 10}
+			for n := newest - (args[0]-1)*10; n >= 0 && n > newest-args[0]*10; n-- {
+				result.Data = append(result.Data, ListedBlock{BlockNum: Number(n), Hash: testHash(dsHash(n))})
+			}
+			return result, ""
+		case "GetDsBlock":
+			var args []string
+			json.Unmarshal(params, &args)
+			n, _ := strconv.ParseInt(args[0], 10, 64)
+			return newTestDsBlock(uint64(n), prevhash(n)), ""
+		}
+		return nil, "METHOD_NOT_FOUND: The method being requested is not available on this server"
+	}
+}
+
+func dsHash(n int64) string {
+	return fmt.Sprintf("%064x", n+0xd5)
+}
+
+func TestDsBlock_Parse(t *testing.T) {
+	Convey("returns the fields of a DS block parsed into typed values", t, func() {
+		block, err := newTestRPC().GetDsBlock("1")
+		So(err, ShouldBeNil)
+		result, err := block.Parse()
+		So(err, ShouldBeNil)
+		So(result.BlockNum.String(), ShouldEqual, "1")
+		So(result.EpochNum, ShouldBeNil)
+		So(result.Timestamp.String(), ShouldEqual, "1549265830654931")
+		So(result.GasPrice.String(), ShouldEqual, "1000000000")
+		So(result.Difficulty, ShouldEqual, 3)
+		So(result.DifficultyDS, ShouldEqual, 5)
+		So(hex.EncodeToString(result.LeaderPubKey), ShouldEqual, "02081dcd3d93a4406e6d90241931a4d8a28553ec7ba28ab5b51d35d992ca2c7383")
+		So(result.PoWWinners, ShouldHaveLength, 1)
+		So(result.PoWWinners[0], ShouldHaveLength, 33)
+		So(hex.EncodeToString(result.Prevhash), ShouldEqual, "0f00e9d3175300fc287812d201edcfbfcb8165809606545595bf53700c524648")
+		So(result.Signature, ShouldHaveLength, 64)
+		So(result.CommitteeHash, ShouldBeNil)
+	})
+
+	Convey("accepts public keys without the 0x prefix", t, func() {
+		block := newTestDsBlock(1, strings.Repeat("00", 32))
+		block.Header.LeaderPubKey = testVectors[0].publicKey
+		result, err := block.Parse()
+		So(err, ShouldBeNil)
+		So(hex.EncodeToString(result.LeaderPubKey), ShouldEqual, testVectors[0].publicKey)
+	})
+
+	Convey("returns an error if a field is invalid", t, func() {
+		block := newTestDsBlock(1, strings.Repeat("00", 32))
+		block.Header.PoWWinners = []string{"0x02"}
+		_, err := block.Parse()
+		So(err, ShouldBeError, "invalid public key 0x02")
+
+		block = newTestDsBlock(1, strings.Repeat("00", 32))
+		block.Signature = "CF8A"
+		_, err = block.Parse()
+		So(err, ShouldBeError, "invalid signature CF8A")
+
+		block = newTestDsBlock(1, strings.Repeat("00", 32))
+		block.Header.Prevhash = testHash("abcd")
+		_, err = block.Parse()
+		So(err, ShouldBeError, "invalid prevhash abcd")
+	})
+}
+
+func TestVerifyDsBlocks(t *testing.T) {
+	Convey("returns DS blocks which link to the listed hashes of the blocks before them", t, func() {
+		node := newStubNode(newDsChainNode(34, func(n int64) string { return dsHash(n - 1) }))
+		defer node.Close()
+
+		blocks, err := VerifyDsBlocks(context.Background(), NewRPC(node.URL), 8, 27)
+		So(err, ShouldBeNil)
+		So(blocks, ShouldHaveLength, 20)
+		So(blocks[0].Header.BlockNum, ShouldEqual, 8)
+		So(blocks[19].Header.BlockNum, ShouldEqual, 27)
+	})
+
+	Convey("returns an error if a block does not link to the block before it", t, func() {
+		node := newStubNode(newDsChainNode(34, func(n int64) string {
+			if n == 12 {
+				return dsHash(20)
+			}
+			return dsHash(n - 1)
+		}))
+		defer node.Close()
+
+		_, err := VerifyDsBlocks(context.Background(), NewRPC(node.URL), 8, 27)
+		So(err, ShouldBeError, "DS block 12 does not link to DS block 11: prevhash is "+dsHash(20)+", expected "+dsHash(11))
+		_, err = VerifyDsBlocks(context.Background(), NewRPC(node.URL), 30, 40)
+		So(err, ShouldBeError, "DS block listing ends before DS block 40")
+		_, err = VerifyDsBlocks(context.Background(), NewRPC(node.URL), 9, 8)
+		So(err, ShouldBeError, "invalid range 9 to 8")
+	})
+}
+
+func TestVerifyDsBlocks_recorded(t *testing.T) {
+	// The DS blocks listed on the recorded first page of DSBlockListing.
+	blocks, err := VerifyDsBlocks(context.Background(), newTestRPC(), 1706, 1715)
+	if err != nil && strings.Contains(err.Error(), "no fixture for") {
+		t.Skipf("record the DS blocks with ZILLEAN_RECORD=1: %v", err)
+	}
+	Convey("verifies a recorded range of DS blocks", t, func() {
+		So(err, ShouldBeNil)
+		So(blocks, ShouldHaveLength, 10)
+	})
+}
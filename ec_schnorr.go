@@ -98,8 +98,13 @@ func (ecs *ECSchnorr) trySign(privKey, pubKey, k, msg []byte) ([]byte, []byte, e
 // 1. Compute Q = sG + r * pubKey
 // 2. r' = H(Q, kpub, m)
 // 3. return r' == r
+//
+// The public key may be compressed or uncompressed.
 func (ecs *ECSchnorr) Verify(r, s, pubKey, msg []byte) bool {
-	pkx, pky := elliptic.Unmarshal(ecs.Curve, pubKey)
+	pkx, pky := ecs.unmarshalPublicKey(pubKey)
+	if pkx == nil {
+		return false
+	}
 	rpkx, rpky := ecs.Curve.ScalarMult(pkx, pky, r)
 	sGx, sGy := ecs.Curve.ScalarBaseMult(s)
 	Qx, Qy := ecs.Curve.Add(sGx, sGy, rpkx, rpky)
@@ -107,3 +112,28 @@ func (ecs *ECSchnorr) Verify(r, s, pubKey, msg []byte) bool {
 
 	return bytes.Equal(r, hash(Q, pubKey, msg))
 }
+
+// unmarshalPublicKey returns the point of a compressed or uncompressed public key, or nil if it is invalid.
+func (ecs *ECSchnorr) unmarshalPublicKey(pubKey []byte) (*big.Int, *big.Int) {
+	params := ecs.Curve.Params()
+	if len(pubKey) != 1+(params.BitSize+7)/8 || (pubKey[0] != 2 && pubKey[0] != 3) {
+		return elliptic.Unmarshal(ecs.Curve, pubKey)
+	}
+
+	// Solve y^2 = x^3 + b, as secp256k1 has a = 0.
+	x := new(big.Int).SetBytes(pubKey[1:])
+	if x.Cmp(params.P) >= 0 {
+		return nil, nil
+	}
+	y := new(big.Int).Mul(x, x)
+	y.Mul(y, x)
+	y.Add(y, params.B)
+	y.Mod(y, params.P)
+	if y.ModSqrt(y, params.P) == nil {
+		return nil, nil
+	}
+	if y.Bit(0) != uint(pubKey[0]&1) {
+		y.Sub(params.P, y)
+	}
+	return x, y
+}
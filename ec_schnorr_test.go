@@ -50,3 +50,23 @@ func TestECSchnorr_Sign_And_Verify(t *testing.T) {
 		So(ecs.Verify(r, s, pubKey, msg), ShouldBeTrue)
 	})
 }
+
+func TestECSchnorr_Verify(t *testing.T) {
+	Convey("validates a signature with a compressed public key", t, func() {
+		ecs := NewECSchnorr()
+		privKey := ecs.GeneratePrivateKey()
+		pubKey := ecs.GetPublicKey(privKey, true)
+		msg := []byte("message")
+		r, s := ecs.Sign(privKey, pubKey, msg)
+		So(ecs.Verify(r, s, pubKey, msg), ShouldBeTrue)
+		So(ecs.Verify(r, s, pubKey, []byte("other message")), ShouldBeFalse)
+	})
+
+	Convey("rejects an invalid public key", t, func() {
+		ecs := NewECSchnorr()
+		privKey := ecs.GeneratePrivateKey()
+		pubKey := ecs.GetPublicKey(privKey, true)
+		r, s := ecs.Sign(privKey, pubKey, []byte("message"))
+		So(ecs.Verify(r, s, pubKey[:20], []byte("message")), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,208 @@
+package zillean
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParamPredicate reports whether the decoded value of an event parameter matches. Values are decoded by DecodeValue.
+type ParamPredicate func(value interface{}) bool
+
+// ParamEquals returns a ParamPredicate which matches values whose string form is s, ignoring case and a 0x prefix,
+// so that both addresses and integers can be compared.
+func ParamEquals(s string) ParamPredicate {
+	s = strings.ToLower(strings.TrimPrefix(s, "0x"))
+	return func(value interface{}) bool {
+		return strings.ToLower(strings.TrimPrefix(fmt.Sprint(value), "0x")) == s
+	}
+}
+
+// EventFilter selects contract events. Empty fields match any event.
+type EventFilter struct {
+	// Addresses are the contracts whose events match.
+	Addresses []string
+	// EventNames are the names of the events which match.
+	EventNames []string
+	// Params are predicates which the parameters of an event must all match. An event without a parameter
+	// of the name does not match.
+	Params map[string]ParamPredicate
+	// FromBlock and ToBlock are the first and last TX blocks scanned. If ToBlock is nil, scanning ends at the latest
+	// block when returning events, and continues with new blocks when streaming them.
+	FromBlock uint64
+	ToBlock   *uint64
+}
+
+// ContractEvent is a contract event matched by an EventFilter.
+type ContractEvent struct {
+	EventLog
+	TxID     string
+	BlockNum uint64
+	// Values are the parameters of the event decoded by DecodeValue.
+	Values map[string]interface{}
+}
+
+// Match reports whether log matches the filter.
+func (f *EventFilter) Match(log EventLog) (bool, error) {
+	_, ok, err := f.match(log)
+	return ok, err
+}
+
+// match reports whether log matches the filter, and returns its decoded parameters if it does.
+func (f *EventFilter) match(log EventLog) (map[string]interface{}, bool, error) {
+	if len(f.Addresses) > 0 {
+		address := strings.ToLower(strings.TrimPrefix(log.Address, "0x"))
+		found := false
+		for _, a := range f.Addresses {
+			if strings.ToLower(strings.TrimPrefix(a, "0x")) == address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false, nil
+		}
+	}
+	if len(f.EventNames) > 0 {
+		found := false
+		for _, name := range f.EventNames {
+			if name == log.EventName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false, nil
+		}
+	}
+
+	values := make(map[string]interface{}, len(log.Params))
+	for _, param := range log.Params {
+		value, err := DecodeValue(param.Type, param.Value)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %s: %v", log.EventName, param.Vname, err)
+		}
+		values[param.Vname] = value
+	}
+	for vname, predicate := range f.Params {
+		value, ok := values[vname]
+		if !ok || !predicate(value) {
+			return nil, false, nil
+		}
+	}
+	return values, true, nil
+}
+
+// EventScanner finds contract events by scanning the receipts of the transactions in TX blocks,
+// since the JSON-RPC API has no query of events.
+type EventScanner struct {
+	RPC *RPC
+	// Concurrency is the number of transactions fetched at once.
+	Concurrency int
+	// Interval is the polling interval for new blocks when streaming events. It defaults to 10s.
+	Interval time.Duration
+}
+
+// NewEventScanner returns a new EventScanner.
+func NewEventScanner(rpc *RPC) *EventScanner {
+	return &EventScanner{RPC: rpc, Concurrency: defaultIndexerConcurrency, Interval: 10 * time.Second}
+}
+
+// FilterEvents returns the events matching filter, in block and transaction order.
+func (s *EventScanner) FilterEvents(ctx context.Context, filter *EventFilter) ([]*ContractEvent, error) {
+	end, err := s.endBlock(filter)
+	if err != nil {
+		return nil, err
+	}
+	var events []*ContractEvent
+	for blockNum := filter.FromBlock; blockNum <= end; blockNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		blockEvents, err := s.BlockEvents(ctx, blockNum, filter)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, blockEvents...)
+	}
+	return events, nil
+}
+
+// StreamEvents sends the events matching filter to events, in block and transaction order, until the last block of
+// the filter is scanned or ctx is done. If the filter has no last block, new blocks are followed with a BlockFollower,
+// whose node errors are retried.
+func (s *EventScanner) StreamEvents(ctx context.Context, filter *EventFilter, events chan<- *ContractEvent) error {
+	send := func(blockNum uint64) error {
+		blockEvents, err := s.BlockEvents(ctx, blockNum, filter)
+		if err != nil {
+			return err
+		}
+		for _, event := range blockEvents {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case events <- event:
+			}
+		}
+		return nil
+	}
+
+	if filter.ToBlock != nil {
+		for blockNum := filter.FromBlock; blockNum <= *filter.ToBlock; blockNum++ {
+			if err := send(blockNum); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	start := filter.FromBlock
+	follower := NewBlockFollower(s.RPC, &FollowerOptions{TxStart: &start, Interval: s.Interval})
+	done := make(chan error, 1)
+	go func() { done <- follower.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	for block := range follower.TxBlocks() {
+		if err := send(uint64(block.Header.BlockNum)); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// BlockEvents returns the events of a TX block matching filter. The block range of the filter is ignored.
+func (s *EventScanner) BlockEvents(ctx context.Context, blockNum uint64, filter *EventFilter) ([]*ContractEvent, error) {
+	txs, err := blockTransactions(ctx, s.RPC, blockNum, s.Concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %v", blockNum, err)
+	}
+	var events []*ContractEvent
+	for _, tx := range txs {
+		for _, log := range tx.Receipt.EventLogs {
+			values, ok, err := filter.match(log)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %s: %v", tx.ID, err)
+			}
+			if ok {
+				events = append(events, &ContractEvent{EventLog: log, TxID: tx.ID, BlockNum: blockNum, Values: values})
+			}
+		}
+	}
+	return events, nil
+}
+
+// endBlock returns the last block of filter, which is the latest TX block if it has none.
+func (s *EventScanner) endBlock(filter *EventFilter) (uint64, error) {
+	if filter.ToBlock != nil {
+		return *filter.ToBlock, nil
+	}
+	latest, err := s.RPC.GetLatestTxBlock()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(latest.Header.BlockNum), nil
+}
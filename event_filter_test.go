@@ -0,0 +1,169 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const otherTokenAddress = "1111111111111111111111111111111111111111"
+
+// newEventNode returns a node stand-in of a chain whose latest TX block is 3. Block 1 holds a transfer of 100
+// tokens to testVectors[1], block 2 is empty, and block 3 holds a transfer of 7 tokens to testVectors[2] and
+// a transfer of another token.
+func newEventNode() func(method string, params json.RawMessage) (interface{}, string) {
+	transfer := func(address, recipient, amount string) map[string]interface{} {
+		return map[string]interface{}{
+			"_eventname": "TransferSuccess",
+			"address":    "0x" + address,
+			"params": []map[string]interface{}{
+				{"vname": "sender", "type": "ByStr20", "value": "0x" + testVectors[0].address},
+				{"vname": "recipient", "type": "ByStr20", "value": "0x" + recipient},
+				{"vname": "amount", "type": "Uint128", "value": amount},
+			},
+		}
+	}
+	txs := map[string]map[string]interface{}{
+		"aa01": {"ID": "aa01", "receipt": map[string]interface{}{"success": true, "event_logs": []interface{}{
+			transfer(tokenAddress, testVectors[1].address, "100"),
+			map[string]interface{}{"_eventname": "Minted", "address": "0x" + tokenAddress, "params": []interface{}{}},
+		}}},
+		"cc01": {"ID": "cc01", "receipt": map[string]interface{}{"success": true, "event_logs": []interface{}{
+			transfer(tokenAddress, testVectors[2].address, "7"),
+		}}},
+		"cc02": {"ID": "cc02", "receipt": map[string]interface{}{"success": true, "event_logs": []interface{}{
+			transfer(otherTokenAddress, testVectors[1].address, "9"),
+		}}},
+	}
+	blocks := map[string][][]string{"1": {{"aa01"}}, "2": {}, "3": {{"cc01"}, {"cc02"}}}
+
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		var args []string
+		json.Unmarshal(params, &args)
+		switch method {
+		case "GetLatestTxBlock":
+			return map[string]interface{}{"header": map[string]interface{}{"BlockNum": "3"}}, ""
+		case "GetNumTxBlocks":
+			return "4", ""
+		case "GetNumDSBlocks":
+			return "1", ""
+		case "GetTxBlock":
+			numTxns := 0
+			for _, hashes := range blocks[args[0]] {
+				numTxns += len(hashes)
+			}
+			return map[string]interface{}{"header": map[string]interface{}{"BlockNum": args[0], "NumTxns": numTxns}}, ""
+		case "GetDsBlock":
+			return map[string]interface{}{"header": map[string]interface{}{"blockNum": args[0]}}, ""
+		case "GetTransactionsForTxBlock":
+			return blocks[args[0]], ""
+		case "GetTransaction":
+			return txs[args[0]], ""
+		}
+		return nil, "METHOD_NOT_FOUND: The method being requested is not available on this server"
+	}
+}
+
+func TestEventFilter_Match(t *testing.T) {
+	Convey("matches events by address, name and parameters", t, func() {
+		log := EventLog{EventName: "TransferSuccess", Address: "0x" + tokenAddress, Params: []ContractParam{
+			{Vname: "recipient", Type: "ByStr20", Value: json.RawMessage(`"0x` + testVectors[1].address + `"`)},
+			{Vname: "amount", Type: "Uint128", Value: json.RawMessage(`"100"`)},
+		}}
+
+		ok, err := (&EventFilter{}).Match(log)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		ok, _ = (&EventFilter{Addresses: []string{"0x" + otherTokenAddress}}).Match(log)
+		So(ok, ShouldBeFalse)
+		ok, _ = (&EventFilter{EventNames: []string{"Minted"}}).Match(log)
+		So(ok, ShouldBeFalse)
+		ok, _ = (&EventFilter{Params: map[string]ParamPredicate{"recipient": ParamEquals(testVectors[1].address)}}).Match(log)
+		So(ok, ShouldBeTrue)
+		ok, _ = (&EventFilter{Params: map[string]ParamPredicate{"amount": ParamEquals("99")}}).Match(log)
+		So(ok, ShouldBeFalse)
+		ok, _ = (&EventFilter{Params: map[string]ParamPredicate{"sender": ParamEquals(testVectors[1].address)}}).Match(log)
+		So(ok, ShouldBeFalse)
+
+		log.Params[1].Value = json.RawMessage(`"abc"`)
+		_, err = (&EventFilter{}).Match(log)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestEventScanner_FilterEvents(t *testing.T) {
+	Convey("returns the matching events of the blocks up to the latest one", t, func() {
+		node := newStubNode(newEventNode())
+		defer node.Close()
+
+		scanner := NewEventScanner(NewRPC(node.URL))
+		events, err := scanner.FilterEvents(context.Background(), &EventFilter{
+			Addresses:  []string{tokenAddress},
+			EventNames: []string{"TransferSuccess"},
+			FromBlock:  1,
+		})
+		So(err, ShouldBeNil)
+		So(len(events), ShouldEqual, 2)
+		So(events[0].TxID, ShouldEqual, "aa01")
+		So(events[0].BlockNum, ShouldEqual, 1)
+		So(events[0].Values["amount"], ShouldResemble, big.NewInt(100))
+		So(events[1].TxID, ShouldEqual, "cc01")
+		So(events[1].BlockNum, ShouldEqual, 3)
+
+		var transfer TransferEvent
+		So(events[1].Unmarshal(&transfer), ShouldBeNil)
+		So(transfer.Amount.Int64(), ShouldEqual, 7)
+
+		end := uint64(2)
+		events, err = scanner.FilterEvents(context.Background(), &EventFilter{
+			Params:  map[string]ParamPredicate{"recipient": ParamEquals(testVectors[1].address)},
+			ToBlock: &end,
+		})
+		So(err, ShouldBeNil)
+		So(len(events), ShouldEqual, 1)
+		So(events[0].TxID, ShouldEqual, "aa01")
+	})
+}
+
+func TestEventScanner_StreamEvents(t *testing.T) {
+	Convey("streams the matching events of a block range", t, func() {
+		node := newStubNode(newEventNode())
+		defer node.Close()
+
+		end := uint64(3)
+		events := make(chan *ContractEvent, 10)
+		err := NewEventScanner(NewRPC(node.URL)).StreamEvents(context.Background(), &EventFilter{
+			Params:  map[string]ParamPredicate{"recipient": ParamEquals(testVectors[1].address)},
+			ToBlock: &end,
+		}, events)
+		So(err, ShouldBeNil)
+		close(events)
+		var txIDs []string
+		for event := range events {
+			txIDs = append(txIDs, event.TxID)
+		}
+		So(txIDs, ShouldResemble, []string{"aa01", "cc02"})
+	})
+
+	Convey("follows new blocks without a last block", t, func() {
+		node := newStubNode(newEventNode())
+		defer node.Close()
+
+		scanner := NewEventScanner(NewRPC(node.URL))
+		scanner.Interval = time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		events := make(chan *ContractEvent)
+		done := make(chan error)
+		go func() {
+			done <- scanner.StreamEvents(ctx, &EventFilter{Addresses: []string{otherTokenAddress}, FromBlock: 1}, events)
+		}()
+
+		So((<-events).TxID, ShouldEqual, "cc02")
+		cancel()
+		So(<-done, ShouldEqual, context.Canceled)
+	})
+}
@@ -0,0 +1,211 @@
+package zillean
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FollowerOptions configures a BlockFollower. Zero values are replaced by the defaults.
+type FollowerOptions struct {
+	// TxStart and DsStart are the numbers of the first blocks to emit. If nil, following starts at the latest block.
+	TxStart *uint64
+	DsStart *uint64
+	// Interval is the polling interval for new blocks. It defaults to 10s.
+	Interval time.Duration
+	// RetryInterval is the first delay before retrying after a node error, which doubles up to MaxRetryInterval.
+	// They default to 1s and 30s.
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+}
+
+// BlockFollower polls a node for new TX and DS blocks and emits every block in order over channels.
+// Blocks missed between polls are fetched one by one, so no block number is skipped, and node errors
+// are retried with backoff.
+type BlockFollower struct {
+	RPC  *RPC
+	opts FollowerOptions
+
+	txBlocks chan *TxBlock
+	dsBlocks chan *DsBlock
+	errors   chan error
+
+	mu     sync.Mutex
+	lastTx *uint64
+	lastDs *uint64
+}
+
+// NewBlockFollower returns a new BlockFollower. opts may be nil.
+func NewBlockFollower(rpc *RPC, opts *FollowerOptions) *BlockFollower {
+	f := &BlockFollower{
+		RPC:      rpc,
+		txBlocks: make(chan *TxBlock),
+		dsBlocks: make(chan *DsBlock),
+		errors:   make(chan error, 16),
+	}
+	if opts != nil {
+		f.opts = *opts
+	}
+	if f.opts.Interval <= 0 {
+		f.opts.Interval = 10 * time.Second
+	}
+	if f.opts.RetryInterval <= 0 {
+		f.opts.RetryInterval = time.Second
+	}
+	if f.opts.MaxRetryInterval <= 0 {
+		f.opts.MaxRetryInterval = 30 * time.Second
+	}
+	if f.opts.MaxRetryInterval < f.opts.RetryInterval {
+		f.opts.MaxRetryInterval = f.opts.RetryInterval
+	}
+	return f
+}
+
+// TxBlocks returns the channel of new TX blocks, which is closed when Run returns.
+func (f *BlockFollower) TxBlocks() <-chan *TxBlock { return f.txBlocks }
+
+// DsBlocks returns the channel of new DS blocks, which is closed when Run returns.
+// TX and DS blocks are followed independently, so either channel may be left unread.
+func (f *BlockFollower) DsBlocks() <-chan *DsBlock { return f.dsBlocks }
+
+// Errors returns the channel of node errors which are being retried. Errors are dropped while the channel is full.
+func (f *BlockFollower) Errors() <-chan error { return f.errors }
+
+// LastTxBlock returns the number of the last emitted TX block, and false if none has been emitted.
+func (f *BlockFollower) LastTxBlock() (uint64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lastTx == nil {
+		return 0, false
+	}
+	return *f.lastTx, true
+}
+
+// LastDsBlock returns the number of the last emitted DS block, and false if none has been emitted.
+func (f *BlockFollower) LastDsBlock() (uint64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lastDs == nil {
+		return 0, false
+	}
+	return *f.lastDs, true
+}
+
+// Run follows the chain until ctx is done, then closes the channels and returns the error of ctx.
+// It must be called only once.
+func (f *BlockFollower) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		f.follow(ctx, "TX", f.opts.TxStart, f.RPC.GetNumTxBlocks, f.emitTxBlock)
+	}()
+	go func() {
+		defer wg.Done()
+		f.follow(ctx, "DS", f.opts.DsStart, f.RPC.GetNumDSBlocks, f.emitDsBlock)
+	}()
+	wg.Wait()
+
+	close(f.txBlocks)
+	close(f.dsBlocks)
+	close(f.errors)
+	return ctx.Err()
+}
+
+// follow emits the blocks of one chain from start, polling the number of blocks with numBlocks.
+func (f *BlockFollower) follow(ctx context.Context, kind string, start *uint64, numBlocks func() (string, error), emit func(context.Context, uint64) error) {
+	var next uint64
+	started := start != nil
+	if started {
+		next = *start
+	}
+
+	retry := f.opts.RetryInterval
+	for {
+		err := func() error {
+			num, err := numBlocks()
+			if err != nil {
+				return err
+			}
+			count, err := strconv.ParseUint(num, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number of %s blocks %s", kind, num)
+			}
+			if count == 0 {
+				return nil
+			}
+			if !started {
+				next, started = count-1, true
+			}
+			for ; next < count; next++ {
+				if err := emit(ctx, next); err != nil {
+					return err
+				}
+			}
+			return nil
+		}()
+
+		wait := f.opts.Interval
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case f.errors <- fmt.Errorf("%s block %d: %v", kind, next, err):
+			default:
+			}
+			wait = retry
+			if retry *= 2; retry > f.opts.MaxRetryInterval {
+				retry = f.opts.MaxRetryInterval
+			}
+		} else {
+			retry = f.opts.RetryInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (f *BlockFollower) emitTxBlock(ctx context.Context, blockNum uint64) error {
+	block, err := f.RPC.GetTxBlock(strconv.FormatUint(blockNum, 10))
+	if err != nil {
+		return err
+	}
+	if uint64(block.Header.BlockNum) != blockNum {
+		return fmt.Errorf("node returned block %d", block.Header.BlockNum)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case f.txBlocks <- block:
+	}
+	f.mu.Lock()
+	f.lastTx = &blockNum
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *BlockFollower) emitDsBlock(ctx context.Context, blockNum uint64) error {
+	block, err := f.RPC.GetDsBlock(strconv.FormatUint(blockNum, 10))
+	if err != nil {
+		return err
+	}
+	if uint64(block.Header.BlockNum) != blockNum {
+		return fmt.Errorf("node returned block %d", block.Header.BlockNum)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case f.dsBlocks <- block:
+	}
+	f.mu.Lock()
+	f.lastDs = &blockNum
+	f.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,110 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newGrowingChainNode returns a node stand-in whose TX chain grows by a block on every GetNumTxBlocks call
+// from txBlocks blocks, and whose DS chain has dsBlocks blocks. Fetching TX block failAt fails once.
+func newGrowingChainNode(txBlocks, dsBlocks, failAt int) func(method string, params json.RawMessage) (interface{}, string) {
+	var mu sync.Mutex
+	failed := false
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		var args []string
+		json.Unmarshal(params, &args)
+		switch method {
+		case "GetNumTxBlocks":
+			num := txBlocks
+			txBlocks++
+			return itoa(num), ""
+		case "GetNumDSBlocks":
+			return itoa(dsBlocks), ""
+		case "GetTxBlock":
+			if args[0] == itoa(failAt) && !failed {
+				failed = true
+				return nil, "Failed to get Tx Block"
+			}
+			return map[string]interface{}{"header": map[string]interface{}{"BlockNum": args[0]}}, ""
+		case "GetDsBlock":
+			return map[string]interface{}{"header": map[string]interface{}{"blockNum": args[0]}}, ""
+		}
+		return nil, "METHOD_NOT_FOUND: The method being requested is not available on this server"
+	}
+}
+
+func itoa(n int) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func TestBlockFollower(t *testing.T) {
+	Convey("emits every block from the start heights in order, retrying node errors", t, func() {
+		node := newStubNode(newGrowingChainNode(10, 3, 7))
+		defer node.Close()
+
+		txStart, dsStart := uint64(5), uint64(1)
+		f := NewBlockFollower(NewRPC(node.URL), &FollowerOptions{
+			TxStart:       &txStart,
+			DsStart:       &dsStart,
+			Interval:      time.Millisecond,
+			RetryInterval: time.Millisecond,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done := make(chan error)
+		go func() { done <- f.Run(ctx) }()
+
+		var txNums []string
+		for len(txNums) < 8 {
+			block := <-f.TxBlocks()
+			txNums = append(txNums, block.Header.BlockNum.String())
+		}
+		var dsNums []string
+		for len(dsNums) < 2 {
+			block := <-f.DsBlocks()
+			dsNums = append(dsNums, block.Header.BlockNum.String())
+		}
+		So(txNums, ShouldResemble, []string{"5", "6", "7", "8", "9", "10", "11", "12"})
+		So(dsNums, ShouldResemble, []string{"1", "2"})
+
+		err := <-f.Errors()
+		So(err.Error(), ShouldEqual, "TX block 7: Failed to get Tx Block")
+
+		cancel()
+		So(<-done, ShouldEqual, context.Canceled)
+		last, ok := f.LastTxBlock()
+		So(ok, ShouldBeTrue)
+		So(last, ShouldEqual, 12)
+		last, ok = f.LastDsBlock()
+		So(ok, ShouldBeTrue)
+		So(last, ShouldEqual, 2)
+		_, open := <-f.TxBlocks()
+		So(open, ShouldBeFalse)
+	})
+
+	Convey("starts at the latest blocks by default", t, func() {
+		node := newStubNode(newGrowingChainNode(10, 3, -1))
+		defer node.Close()
+
+		f := NewBlockFollower(NewRPC(node.URL), &FollowerOptions{Interval: time.Millisecond})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- f.Run(ctx) }()
+
+		So((<-f.TxBlocks()).Header.BlockNum, ShouldEqual, Number(9))
+		So((<-f.DsBlocks()).Header.BlockNum, ShouldEqual, Number(2))
+		cancel()
+		<-done
+		last, ok := f.LastDsBlock()
+		So(ok, ShouldBeTrue)
+		So(last, ShouldEqual, 2)
+	})
+}
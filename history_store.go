@@ -0,0 +1,196 @@
+package zillean
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// MemoryHistoryStore is a HistoryStore which keeps the history in memory.
+type MemoryHistoryStore struct {
+	mu        sync.RWMutex
+	blocks    map[uint64][]HistoryEntry
+	addresses map[string][]HistoryEntry
+	last      uint64
+	indexed   bool
+}
+
+// NewMemoryHistoryStore returns a new empty MemoryHistoryStore.
+func NewMemoryHistoryStore() *MemoryHistoryStore {
+	return &MemoryHistoryStore{
+		blocks:    map[uint64][]HistoryEntry{},
+		addresses: map[string][]HistoryEntry{},
+	}
+}
+
+// AddBlock implements HistoryStore.
+func (s *MemoryHistoryStore) AddBlock(blockNum uint64, entries []HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.blocks[blockNum]; ok {
+		for _, entry := range old {
+			history := s.addresses[entry.Address][:0]
+			for _, e := range s.addresses[entry.Address] {
+				if e.BlockNum != blockNum {
+					history = append(history, e)
+				}
+			}
+			s.addresses[entry.Address] = history
+		}
+	}
+	s.blocks[blockNum] = append([]HistoryEntry{}, entries...)
+	for _, entry := range entries {
+		history := s.addresses[entry.Address]
+		i := sort.Search(len(history), func(i int) bool { return history[i].BlockNum > blockNum })
+		history = append(history, HistoryEntry{})
+		copy(history[i+1:], history[i:])
+		history[i] = entry
+		s.addresses[entry.Address] = history
+	}
+	if !s.indexed || blockNum > s.last {
+		s.last, s.indexed = blockNum, true
+	}
+	return nil
+}
+
+// History implements HistoryStore.
+func (s *MemoryHistoryStore) History(address string, start, end uint64) ([]HistoryEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := s.addresses[address]
+	i := sort.Search(len(history), func(i int) bool { return history[i].BlockNum >= start })
+	j := sort.Search(len(history), func(i int) bool { return history[i].BlockNum > end })
+	if i >= j {
+		return nil, nil
+	}
+	return append([]HistoryEntry{}, history[i:j]...), nil
+}
+
+// LastBlock implements HistoryStore.
+func (s *MemoryHistoryStore) LastBlock() (uint64, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last, s.indexed, nil
+}
+
+// FileHistoryStore is a MemoryHistoryStore persisted to a file. It appends the indexed blocks to the file,
+// one JSON record per line, and replays the whole file into memory when it is opened, so its memory grows with
+// the indexed history and queries never read the disk. A record which was partially written when the process
+// stopped is dropped when the file is opened again, so the block is indexed again.
+type FileHistoryStore struct {
+	mu     sync.Mutex
+	file   *os.File
+	memory *MemoryHistoryStore
+	// offset is the end of the last complete record. err is set if a failed write could not be rolled back,
+	// after which the store fails every write.
+	offset int64
+	err    error
+}
+
+type historyRecord struct {
+	BlockNum uint64         `json:"blockNum"`
+	Entries  []HistoryEntry `json:"entries"`
+}
+
+// OpenFileHistoryStore opens the FileHistoryStore at path, creating the file if it does not exist.
+// It returns an error if a record other than a partial last one is corrupt.
+func OpenFileHistoryStore(path string) (*FileHistoryStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileHistoryStore{file: file, memory: NewMemoryHistoryStore()}
+
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A last line without a newline is a partial record.
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		var record historyRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("corrupt history record at offset %d: %v", offset, err)
+		}
+		s.memory.AddBlock(record.BlockNum, record.Entries)
+		offset += int64(len(line))
+	}
+	// Drop a partial record at the end of the file.
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	s.offset = offset
+	return s, nil
+}
+
+// AddBlock implements HistoryStore. The record is synced to the disk before the block counts as indexed.
+// If the write fails, the partial record is removed from the file.
+func (s *FileHistoryStore) AddBlock(blockNum uint64, entries []HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+
+	data, err := json.Marshal(historyRecord{blockNum, entries})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := s.file.Write(data); err != nil {
+		return s.rollback(err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return s.rollback(err)
+	}
+	s.offset += int64(len(data))
+	return s.memory.AddBlock(blockNum, entries)
+}
+
+// rollback truncates the file to the last complete record after a failed write, and returns err.
+// If the file cannot be truncated, the store fails every later write.
+func (s *FileHistoryStore) rollback(err error) error {
+	if terr := s.file.Truncate(s.offset); terr != nil {
+		s.err = fmt.Errorf("history store failed: %v after %v", terr, err)
+		return s.err
+	}
+	if _, serr := s.file.Seek(s.offset, io.SeekStart); serr != nil {
+		s.err = fmt.Errorf("history store failed: %v after %v", serr, err)
+		return s.err
+	}
+	return err
+}
+
+// History implements HistoryStore.
+func (s *FileHistoryStore) History(address string, start, end uint64) ([]HistoryEntry, error) {
+	return s.memory.History(address, start, end)
+}
+
+// LastBlock implements HistoryStore.
+func (s *FileHistoryStore) LastBlock() (uint64, bool, error) {
+	return s.memory.LastBlock()
+}
+
+// Close closes the file of the store.
+func (s *FileHistoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
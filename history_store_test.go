@@ -0,0 +1,121 @@
+package zillean
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMemoryHistoryStore(t *testing.T) {
+	Convey("returns the history of an address by block range", t, func() {
+		store := NewMemoryHistoryStore()
+		_, ok, err := store.LastBlock()
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+
+		So(store.AddBlock(3, []HistoryEntry{{Address: "a", TxID: "3a", BlockNum: 3}}), ShouldBeNil)
+		So(store.AddBlock(1, []HistoryEntry{{Address: "a", TxID: "1a", BlockNum: 1}, {Address: "b", TxID: "1b", BlockNum: 1}}), ShouldBeNil)
+		So(store.AddBlock(2, []HistoryEntry{{Address: "a", TxID: "2a", BlockNum: 2}}), ShouldBeNil)
+
+		history, err := store.History("a", 0, 10)
+		So(err, ShouldBeNil)
+		So(len(history), ShouldEqual, 3)
+		So(history[0].TxID, ShouldEqual, "1a")
+		So(history[2].TxID, ShouldEqual, "3a")
+
+		history, err = store.History("a", 2, 2)
+		So(err, ShouldBeNil)
+		So(len(history), ShouldEqual, 1)
+		So(history[0].TxID, ShouldEqual, "2a")
+
+		last, ok, err := store.LastBlock()
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		So(last, ShouldEqual, 3)
+	})
+
+	Convey("replaces the entries of a block which is added again", t, func() {
+		store := NewMemoryHistoryStore()
+		store.AddBlock(1, []HistoryEntry{{Address: "a", TxID: "old", BlockNum: 1}})
+		store.AddBlock(1, []HistoryEntry{{Address: "a", TxID: "new", BlockNum: 1}})
+
+		history, _ := store.History("a", 0, 10)
+		So(len(history), ShouldEqual, 1)
+		So(history[0].TxID, ShouldEqual, "new")
+	})
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	Convey("keeps the history across reopening", t, func() {
+		dir, err := ioutil.TempDir("", "zillean")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "history.jsonl")
+
+		store, err := OpenFileHistoryStore(path)
+		So(err, ShouldBeNil)
+		So(store.AddBlock(1, []HistoryEntry{{Address: "a", Direction: Incoming, TxID: "1a", BlockNum: 1, Amount: "10", Success: true}}), ShouldBeNil)
+		So(store.AddBlock(2, nil), ShouldBeNil)
+		So(store.Close(), ShouldBeNil)
+
+		// Simulate a crash while writing the record of block 3.
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		So(err, ShouldBeNil)
+		file.WriteString(`{"blockNum":3,"entries":[{"addr`)
+		file.Close()
+
+		store, err = OpenFileHistoryStore(path)
+		So(err, ShouldBeNil)
+		defer store.Close()
+		last, ok, err := store.LastBlock()
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		So(last, ShouldEqual, 2)
+
+		history, err := store.History("a", 0, 10)
+		So(err, ShouldBeNil)
+		So(history, ShouldResemble, []HistoryEntry{{Address: "a", Direction: Incoming, TxID: "1a", BlockNum: 1, Amount: "10", Success: true}})
+
+		So(store.AddBlock(3, []HistoryEntry{{Address: "a", TxID: "3a", BlockNum: 3}}), ShouldBeNil)
+		data, err := ioutil.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEndWith, "\"entries\":[{\"address\":\"a\",\"direction\":0,\"txID\":\"3a\",\"blockNum\":3,\"from\":\"\",\"to\":\"\",\"amount\":\"\",\"success\":false}]}\n")
+	})
+
+	Convey("returns an error if a record before the last one is corrupt", t, func() {
+		dir, err := ioutil.TempDir("", "zillean")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "history.jsonl")
+		records := "{\"blockNum\":1,\"entries\":null}\n{\"blockNum\":2,\"ent\n{\"blockNum\":3,\"entries\":null}\n"
+		So(ioutil.WriteFile(path, []byte(records), 0644), ShouldBeNil)
+
+		_, err = OpenFileHistoryStore(path)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, "corrupt history record at offset 30")
+		data, err := ioutil.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, records)
+	})
+
+	Convey("fails every write after a write which could not be rolled back", t, func() {
+		dir, err := ioutil.TempDir("", "zillean")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "history.jsonl")
+
+		store, err := OpenFileHistoryStore(path)
+		So(err, ShouldBeNil)
+		So(store.AddBlock(1, nil), ShouldBeNil)
+		store.file.Close()
+		err = store.AddBlock(2, nil)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, "history store failed")
+		So(store.AddBlock(3, nil), ShouldEqual, err)
+		last, _, _ := store.LastBlock()
+		So(last, ShouldEqual, 1)
+	})
+}
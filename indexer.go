@@ -0,0 +1,225 @@
+package zillean
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultIndexerConcurrency is the number of transactions which an Indexer fetches at once by default.
+const defaultIndexerConcurrency = 8
+
+// TxDirection represents whether a transaction in the history of an address was sent or received by it.
+type TxDirection int
+
+// Directions of a transaction relative to an address.
+const (
+	Outgoing TxDirection = iota
+	Incoming
+)
+
+func (d TxDirection) String() string {
+	if d == Incoming {
+		return "incoming"
+	}
+	return "outgoing"
+}
+
+// HistoryEntry describes a transaction in the history of an address.
+// Address is the address whose history the entry belongs to, which is From for outgoing
+// and To for incoming transactions. Addresses are lowercase hex without the 0x prefix.
+type HistoryEntry struct {
+	Address   string      `json:"address"`
+	Direction TxDirection `json:"direction"`
+	TxID      string      `json:"txID"`
+	BlockNum  uint64      `json:"blockNum"`
+	From      string      `json:"from"`
+	To        string      `json:"to"`
+	Amount    string      `json:"amount"`
+	Success   bool        `json:"success"`
+	// Internal is set for ZIL sent by a contract in a transition of the transaction.
+	Internal bool `json:"internal,omitempty"`
+}
+
+// HistoryStore persists the history of addresses built by an Indexer.
+type HistoryStore interface {
+	// AddBlock stores the entries of a TX block and advances the last indexed block to it.
+	// Adding a block which is already indexed replaces its entries.
+	AddBlock(blockNum uint64, entries []HistoryEntry) error
+	// History returns the entries of an address in the blocks from start to end inclusive, in block order.
+	History(address string, start, end uint64) ([]HistoryEntry, error)
+	// LastBlock returns the number of the last indexed block, and false if no block is indexed.
+	LastBlock() (uint64, bool, error)
+}
+
+// Indexer builds the incoming and outgoing transaction history of addresses by walking TX blocks,
+// since the JSON-RPC API has no query of transactions by address.
+type Indexer struct {
+	RPC   *RPC
+	Store HistoryStore
+	// Concurrency is the number of transactions fetched at once.
+	Concurrency int
+}
+
+// NewIndexer returns a new Indexer which stores the history in store.
+func NewIndexer(rpc *RPC, store HistoryStore) *Indexer {
+	return &Indexer{RPC: rpc, Store: store, Concurrency: defaultIndexerConcurrency}
+}
+
+// Sync indexes the blocks after the last indexed one, or from start if no block is indexed, up to the latest
+// TX block, and returns the number of the last indexed block, and false if no block is indexed yet.
+func (ix *Indexer) Sync(ctx context.Context, start uint64) (uint64, bool, error) {
+	last, ok, err := ix.Store.LastBlock()
+	if err != nil {
+		return 0, false, err
+	}
+	if ok {
+		start = last + 1
+	}
+
+	latest, err := ix.RPC.GetLatestTxBlock()
+	if err != nil {
+		return 0, false, err
+	}
+	end := uint64(latest.Header.BlockNum)
+	if start > end {
+		return last, ok, nil
+	}
+	if err := ix.IndexRange(ctx, start, end); err != nil {
+		return 0, false, err
+	}
+	return end, true, nil
+}
+
+// IndexRange indexes the TX blocks from start to end inclusive, in order.
+func (ix *Indexer) IndexRange(ctx context.Context, start, end uint64) error {
+	for blockNum := start; blockNum <= end; blockNum++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := ix.IndexBlock(ctx, blockNum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IndexBlock fetches the transactions of a TX block and stores an outgoing entry for the sender
+// and an incoming entry for the recipient of each transaction. ZIL sent by contracts in the transitions
+// of a successful transaction is stored likewise, with Internal set.
+func (ix *Indexer) IndexBlock(ctx context.Context, blockNum uint64) error {
+	txs, err := blockTransactions(ctx, ix.RPC, blockNum, ix.Concurrency)
+	if err != nil {
+		return fmt.Errorf("block %d: %v", blockNum, err)
+	}
+	var entries []HistoryEntry
+	for _, tx := range txs {
+		publicKey, err := hex.DecodeString(strings.TrimPrefix(tx.SenderPubKey, "0x"))
+		if err != nil {
+			return fmt.Errorf("transaction %s: invalid sender public key", tx.ID)
+		}
+		entry := HistoryEntry{
+			TxID:     tx.ID,
+			BlockNum: blockNum,
+			From:     publicKeyToAddress(publicKey),
+			To:       strings.ToLower(strings.TrimPrefix(tx.ToAddr, "0x")),
+			Amount:   tx.Amount,
+			Success:  tx.Receipt.Success,
+		}
+		entries = appendHistoryEntries(entries, entry)
+
+		if !tx.Receipt.Success {
+			continue
+		}
+		for _, transition := range tx.Receipt.Transitions {
+			if amount := transition.Msg.Amount; amount == "" || amount == "0" {
+				continue
+			}
+			entries = appendHistoryEntries(entries, HistoryEntry{
+				TxID:     tx.ID,
+				BlockNum: blockNum,
+				From:     strings.ToLower(strings.TrimPrefix(transition.Addr, "0x")),
+				To:       strings.ToLower(strings.TrimPrefix(transition.Msg.Recipient, "0x")),
+				Amount:   transition.Msg.Amount,
+				Success:  true,
+				Internal: true,
+			})
+		}
+	}
+	return ix.Store.AddBlock(blockNum, entries)
+}
+
+// appendHistoryEntries appends the outgoing entry of the sender and the incoming entry of the recipient of
+// a transfer.
+func appendHistoryEntries(entries []HistoryEntry, entry HistoryEntry) []HistoryEntry {
+	outgoing, incoming := entry, entry
+	outgoing.Address, outgoing.Direction = entry.From, Outgoing
+	incoming.Address, incoming.Direction = entry.To, Incoming
+	return append(entries, outgoing, incoming)
+}
+
+// History returns the entries of an address in the blocks from start to end inclusive.
+func (ix *Indexer) History(address string, start, end uint64) ([]HistoryEntry, error) {
+	return ix.Store.History(strings.ToLower(strings.TrimPrefix(address, "0x")), start, end)
+}
+
+// blockTransactions fetches the transactions of a TX block with up to concurrency requests at once,
+// keeping their order.
+func blockTransactions(ctx context.Context, rpc *RPC, blockNum uint64, concurrency int) ([]*Transaction, error) {
+	block, err := rpc.GetTxBlock(strconv.FormatUint(blockNum, 10))
+	if err != nil {
+		return nil, err
+	}
+	if block.Header.NumTxns == 0 {
+		return nil, nil
+	}
+	microBlocks, err := rpc.GetTransactionsForTxBlock(strconv.FormatUint(blockNum, 10))
+	if err != nil {
+		return nil, err
+	}
+	var txIDs []string
+	for _, hashes := range microBlocks {
+		txIDs = append(txIDs, hashes...)
+	}
+	return fetchTransactions(ctx, rpc, txIDs, concurrency)
+}
+
+// fetchTransactions fetches transactions with up to concurrency requests at once, keeping their order.
+func fetchTransactions(ctx context.Context, rpc *RPC, txIDs []string, concurrency int) ([]*Transaction, error) {
+	if concurrency <= 0 {
+		concurrency = defaultIndexerConcurrency
+	}
+
+	txs := make([]*Transaction, len(txIDs))
+	errs := make([]error, len(txIDs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, txID := range txIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, txID string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			txs[i], errs[i] = rpc.GetTransaction(txID)
+		}(i, txID)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %v", txIDs[i], err)
+		}
+	}
+	return txs, nil
+}
@@ -0,0 +1,156 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newChainNode returns a node stand-in of a chain whose latest TX block is 3. Block 1 holds a transfer from
+// testVectors[0] to testVectors[1], a failed transfer back, and a contract call by testVectors[2] in which the
+// contract sends 9 to testVectors[3]. Block 2 is empty, and block 3 holds a transfer from testVectors[1] to
+// testVectors[2].
+func newChainNode() func(method string, params json.RawMessage) (interface{}, string) {
+	txs := map[string]map[string]interface{}{
+		"aa01": {"ID": "aa01", "amount": "100", "senderPubKey": "0x" + testVectors[0].publicKey, "toAddr": testVectors[1].address,
+			"receipt": map[string]interface{}{"success": true}},
+		"aa02": {"ID": "aa02", "amount": "5", "senderPubKey": "0x" + testVectors[1].publicKey, "toAddr": testVectors[0].address,
+			"receipt": map[string]interface{}{"success": false}},
+		"aa03": {"ID": "aa03", "amount": "0", "senderPubKey": "0x" + testVectors[2].publicKey, "toAddr": "6c1169e8a77d34d6d615862db5f62f0a9791cb9f",
+			"receipt": map[string]interface{}{"success": true, "transitions": []map[string]interface{}{
+				{"addr": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "depth": 0, "msg": map[string]interface{}{"_amount": "9", "_recipient": "0x" + testVectors[3].address, "_tag": ""}},
+				{"addr": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "depth": 0, "msg": map[string]interface{}{"_amount": "0", "_recipient": "0x" + testVectors[4].address, "_tag": "Notify"}},
+			}}},
+		"cc01": {"ID": "cc01", "amount": "7", "senderPubKey": "0x" + testVectors[1].publicKey, "toAddr": "0x" + testVectors[2].address,
+			"receipt": map[string]interface{}{"success": true}},
+	}
+	blocks := map[string][][]string{"1": {{"aa01"}, {"aa02", "aa03"}}, "2": {}, "3": {{"cc01"}}}
+
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		var args []string
+		json.Unmarshal(params, &args)
+		switch method {
+		case "GetLatestTxBlock":
+			return map[string]interface{}{"header": map[string]interface{}{"BlockNum": "3"}}, ""
+		case "GetTxBlock":
+			numTxns := 0
+			for _, hashes := range blocks[args[0]] {
+				numTxns += len(hashes)
+			}
+			return map[string]interface{}{"header": map[string]interface{}{"BlockNum": args[0], "NumTxns": numTxns}}, ""
+		case "GetTransactionsForTxBlock":
+			if len(blocks[args[0]]) == 0 {
+				return nil, "TxBlock has no transactions"
+			}
+			return blocks[args[0]], ""
+		case "GetTransaction":
+			return txs[args[0]], ""
+		}
+		return nil, "METHOD_NOT_FOUND: The method being requested is not available on this server"
+	}
+}
+
+func TestIndexer_Sync(t *testing.T) {
+	Convey("indexes the incoming and outgoing transactions of addresses up to the latest block", t, func() {
+		node := newStubNode(newChainNode())
+		defer node.Close()
+
+		ix := NewIndexer(NewRPC(node.URL), NewMemoryHistoryStore())
+		last, ok, err := ix.Sync(context.Background(), 1)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		So(last, ShouldEqual, 3)
+
+		history, err := ix.History("0x"+testVectors[1].address, 0, 10)
+		So(err, ShouldBeNil)
+		So(len(history), ShouldEqual, 3)
+		So(history[0].TxID, ShouldEqual, "aa01")
+		So(history[0].Direction, ShouldEqual, Incoming)
+		So(history[0].From, ShouldEqual, testVectors[0].address)
+		So(history[0].Amount, ShouldEqual, "100")
+		So(history[1].TxID, ShouldEqual, "aa02")
+		So(history[1].Direction, ShouldEqual, Outgoing)
+		So(history[1].Success, ShouldBeFalse)
+		So(history[2].TxID, ShouldEqual, "cc01")
+		So(history[2].BlockNum, ShouldEqual, 3)
+		So(history[2].To, ShouldEqual, testVectors[2].address)
+
+		history, err = ix.History(testVectors[1].address, 2, 3)
+		So(err, ShouldBeNil)
+		So(len(history), ShouldEqual, 1)
+
+		last, ok, err = ix.Sync(context.Background(), 1)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		So(last, ShouldEqual, 3)
+	})
+
+	Convey("indexes ZIL sent by contracts in transitions", t, func() {
+		node := newStubNode(newChainNode())
+		defer node.Close()
+
+		ix := NewIndexer(NewRPC(node.URL), NewMemoryHistoryStore())
+		So(ix.IndexBlock(context.Background(), 1), ShouldBeNil)
+		history, err := ix.History(testVectors[3].address, 0, 10)
+		So(err, ShouldBeNil)
+		So(history, ShouldResemble, []HistoryEntry{{
+			Address:   testVectors[3].address,
+			Direction: Incoming,
+			TxID:      "aa03",
+			BlockNum:  1,
+			From:      "6c1169e8a77d34d6d615862db5f62f0a9791cb9f",
+			To:        testVectors[3].address,
+			Amount:    "9",
+			Success:   true,
+			Internal:  true,
+		}})
+		history, err = ix.History("6c1169e8a77d34d6d615862db5f62f0a9791cb9f", 0, 10)
+		So(err, ShouldBeNil)
+		So(len(history), ShouldEqual, 2)
+		So(history[1].Direction, ShouldEqual, Outgoing)
+		So(history[1].Internal, ShouldBeTrue)
+		history, err = ix.History(testVectors[4].address, 0, 10)
+		So(err, ShouldBeNil)
+		So(history, ShouldBeEmpty)
+	})
+
+	Convey("reports that no block is indexed when start is after the latest block", t, func() {
+		node := newStubNode(newChainNode())
+		defer node.Close()
+
+		last, ok, err := NewIndexer(NewRPC(node.URL), NewMemoryHistoryStore()).Sync(context.Background(), 10)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+		So(last, ShouldEqual, 0)
+	})
+
+	Convey("resumes after the last indexed block", t, func() {
+		node := newStubNode(newChainNode())
+		defer node.Close()
+
+		store := NewMemoryHistoryStore()
+		store.AddBlock(2, nil)
+		ix := NewIndexer(NewRPC(node.URL), store)
+		_, _, err := ix.Sync(context.Background(), 0)
+		So(err, ShouldBeNil)
+
+		history, err := ix.History(testVectors[0].address, 0, 10)
+		So(err, ShouldBeNil)
+		So(len(history), ShouldBeZeroValue)
+		history, err = ix.History(testVectors[2].address, 0, 10)
+		So(err, ShouldBeNil)
+		So(len(history), ShouldEqual, 1)
+	})
+
+	Convey("returns an error naming the block which cannot be indexed", t, func() {
+		node := newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+			return nil, "Failed to get Tx Block"
+		})
+		defer node.Close()
+
+		err := NewIndexer(NewRPC(node.URL), NewMemoryHistoryStore()).IndexRange(context.Background(), 5, 6)
+		So(err.Error(), ShouldEqual, "block 5: Failed to get Tx Block")
+	})
+}
@@ -0,0 +1,164 @@
+package zillean
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number is an unsigned integer which the node returns as a decimal string, or sometimes as a JSON number.
+// It is marshaled back to a decimal string.
+type Number uint64
+
+// String returns the decimal form of the integer, as returned by the node.
+func (u Number) String() string {
+	return strconv.FormatUint(uint64(u), 10)
+}
+
+// Uint64 returns the integer as a uint64.
+func (u Number) Uint64() uint64 {
+	return uint64(u)
+}
+
+// Int64 returns the integer as an int64, the type of the block numbers of ListedBlocks before Number.
+func (u Number) Int64() int64 {
+	return int64(u)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (u Number) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (u *Number) UnmarshalJSON(data []byte) error {
+	s, err := unquoteNumber(data)
+	if err != nil || s == "" {
+		return err
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", s)
+	}
+	*u = Number(v)
+	return nil
+}
+
+// BigInt is an integer of arbitrary size, such as an amount in Qa, which the node returns as a decimal string.
+// It is marshaled back to a decimal string.
+type BigInt big.Int
+
+// Int returns the integer as a *big.Int, which shares its value.
+func (i *BigInt) Int() *big.Int {
+	return (*big.Int)(i)
+}
+
+// String returns the decimal form of the integer, as returned by the node.
+func (i BigInt) String() string {
+	return i.Int().String()
+}
+
+// MarshalJSON implements json.Marshaler.
+func (i BigInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (i *BigInt) UnmarshalJSON(data []byte) error {
+	s, err := unquoteNumber(data)
+	if err != nil || s == "" {
+		return err
+	}
+	if _, ok := i.Int().SetString(s, 10); !ok {
+		return fmt.Errorf("invalid integer %s", s)
+	}
+	return nil
+}
+
+// Timestamp is a time which the node returns as a decimal string of microseconds since the Unix epoch.
+// It is marshaled back to microseconds.
+type Timestamp struct {
+	time.Time
+}
+
+// Micros returns the number of microseconds since the Unix epoch, as returned by the node, or 0 for the zero time.
+func (t Timestamp) Micros() uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.Unix())*1000000 + uint64(t.Nanosecond()/1000)
+}
+
+// String returns the decimal form of the microseconds since the Unix epoch, as returned by the node.
+// Format the embedded Time for other forms.
+func (t Timestamp) String() string {
+	return strconv.FormatUint(t.Micros(), 10)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	s, err := unquoteNumber(data)
+	if err != nil || s == "" {
+		return err
+	}
+	v, err := strconv.ParseUint(s, 10, 63)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s", s)
+	}
+	t.Time = time.Unix(int64(v/1000000), int64(v%1000000)*1000).UTC()
+	return nil
+}
+
+// Hash is a hash which the node returns as a hex string, with or without the 0x prefix.
+// It is marshaled back to lowercase hex without the prefix.
+type Hash []byte
+
+// String returns the lowercase hex form of the hash, without the 0x prefix. This is the form returned by nodes
+// for block hashes, but hashes sent in upper case or with the prefix are normalized.
+func (h Hash) String() string {
+	return hex.EncodeToString(h)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(s), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid hash %s", s)
+	}
+	if len(b) == 0 {
+		b = nil
+	}
+	*h = b
+	return nil
+}
+
+// unquoteNumber returns the string form of a JSON number or string. It returns an empty string for null.
+func unquoteNumber(data []byte) (string, error) {
+	if string(data) == "null" {
+		return "", nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		err := json.Unmarshal(data, &s)
+		return s, err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,88 @@
+package zillean
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testHash returns the Hash of a hex string.
+func testHash(s string) Hash {
+	h, _ := hex.DecodeString(s)
+	return h
+}
+
+func TestNumber(t *testing.T) {
+	Convey("decodes a decimal string or a JSON number", t, func() {
+		var v struct{ A, B, C Number }
+		So(json.Unmarshal([]byte(`{"A":"100","B":7}`), &v), ShouldBeNil)
+		So(v.A, ShouldEqual, 100)
+		So(v.B, ShouldEqual, 7)
+		So(v.C, ShouldEqual, 0)
+		So(v.A.String(), ShouldEqual, "100")
+		So(v.A.Int64(), ShouldEqual, int64(100))
+		So(v.B.Uint64(), ShouldEqual, uint64(7))
+		So(json.Unmarshal([]byte(`{"A":"-1"}`), &v), ShouldBeError, "invalid integer -1")
+	})
+
+	Convey("encodes a decimal string", t, func() {
+		b, err := json.Marshal(Number(18446744073709551615))
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `"18446744073709551615"`)
+	})
+}
+
+func TestBigInt(t *testing.T) {
+	Convey("decodes and encodes a decimal string", t, func() {
+		var v struct{ A BigInt }
+		So(json.Unmarshal([]byte(`{"A":"340282366920938463463374607431768211455"}`), &v), ShouldBeNil)
+		So(v.A.Int().BitLen(), ShouldEqual, 128)
+		So(v.A.String(), ShouldEqual, "340282366920938463463374607431768211455")
+
+		b, err := json.Marshal(v)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"A":"340282366920938463463374607431768211455"}`)
+		So(json.Unmarshal([]byte(`{"A":"1.5"}`), &v), ShouldBeError, "invalid integer 1.5")
+	})
+}
+
+func TestTimestamp(t *testing.T) {
+	Convey("decodes and encodes microseconds since the Unix epoch", t, func() {
+		var v struct{ A Timestamp }
+		So(json.Unmarshal([]byte(`{"A":"1549267096666600"}`), &v), ShouldBeNil)
+		So(v.A.Equal(time.Date(2019, 2, 4, 7, 58, 16, 666600000, time.UTC)), ShouldBeTrue)
+		So(v.A.Micros(), ShouldEqual, 1549267096666600)
+		So(v.A.String(), ShouldEqual, "1549267096666600")
+
+		b, err := json.Marshal(v)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"A":"1549267096666600"}`)
+	})
+
+	Convey("leaves a missing timestamp zero", t, func() {
+		var v struct{ A Timestamp }
+		So(json.Unmarshal([]byte(`{}`), &v), ShouldBeNil)
+		So(v.A.IsZero(), ShouldBeTrue)
+		So(v.A.Micros(), ShouldEqual, 0)
+	})
+}
+
+func TestHash_UnmarshalJSON(t *testing.T) {
+	Convey("decodes hex with or without the 0x prefix", t, func() {
+		var v struct{ A, B, C Hash }
+		So(json.Unmarshal([]byte(`{"A":"0xABCD","B":"abcd","C":""}`), &v), ShouldBeNil)
+		So([]byte(v.A), ShouldResemble, []byte{0xab, 0xcd})
+		So(v.B.String(), ShouldEqual, "abcd")
+		So(v.C, ShouldBeNil)
+		So(json.Unmarshal([]byte(`{"A":"xyz"}`), &v), ShouldBeError, "invalid hash xyz")
+	})
+
+	Convey("encodes lowercase hex", t, func() {
+		b, err := json.Marshal(Hash{0xab, 0xcd})
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `"abcd"`)
+	})
+}
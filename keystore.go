@@ -0,0 +1,193 @@
+package zillean
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Key derivation functions of a keystore.
+const (
+	KDFPBKDF2 = "pbkdf2"
+	KDFScrypt = "scrypt"
+)
+
+// keystoreCipher is the cipher of the private key, which is also appended to the MAC input.
+const keystoreCipher = "aes-128-ctr"
+
+// Limits of the key derivation parameters of a keystore, which keep a crafted keystore from taking hours or
+// gigabytes to decrypt. They are well above the parameters used by the Zilliqa JavaScript library.
+const (
+	maxKeystoreC     = 1 << 22
+	maxKeystoreN     = 1 << 18
+	maxKeystoreR     = 8
+	maxKeystoreP     = 8
+	maxKeystoreDKLen = 64
+)
+
+// Keystore describes a private key encrypted with a passphrase, in the format of the Zilliqa JavaScript library.
+type Keystore struct {
+	Address string `json:"address"`
+	Crypto  struct {
+		Cipher       string `json:"cipher"`
+		CipherParams struct {
+			IV string `json:"iv"`
+		} `json:"cipherparams"`
+		CipherText string            `json:"ciphertext"`
+		KDF        string            `json:"kdf"`
+		KDFParams  KeystoreKDFParams `json:"kdfparams"`
+		MAC        string            `json:"mac"`
+	} `json:"crypto"`
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+}
+
+// KeystoreKDFParams describes the parameters of the key derivation function of a keystore.
+// C is used by pbkdf2, and N, R and P by scrypt.
+type KeystoreKDFParams struct {
+	Salt  string `json:"salt"`
+	N     int    `json:"n"`
+	C     int    `json:"c"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+}
+
+// EncryptPrivateKey returns the keystore of a private key encrypted with passphrase,
+// using kdf, which is KDFPBKDF2 or KDFScrypt, to derive the encryption key.
+func (z *Zillean) EncryptPrivateKey(privateKey, passphrase, kdf string) ([]byte, error) {
+	if ok, err := z.VerifyPrivateKey(privateKey); !ok {
+		return nil, err
+	}
+	address, err := z.GetAddressFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	checksumAddress, err := z.ToChecksumAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if kdf != KDFPBKDF2 && kdf != KDFScrypt {
+		return nil, fmt.Errorf("unsupported kdf %s", kdf)
+	}
+
+	var ks Keystore
+	ks.Address = checksumAddress
+	ks.Crypto.Cipher = keystoreCipher
+	ks.Crypto.KDF = kdf
+	ks.Crypto.KDFParams = KeystoreKDFParams{Salt: hex.EncodeToString(generateRandomBytes(32)), N: 8192, C: 262144, R: 8, P: 1, DKLen: 32}
+	derivedKey, err := deriveKey(passphrase, kdf, ks.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := generateRandomBytes(16)
+	key, _ := hex.DecodeString(privateKey)
+	cipherText, err := aesCTR(derivedKey[:16], iv, key)
+	if err != nil {
+		return nil, err
+	}
+	ks.Crypto.CipherParams.IV = hex.EncodeToString(iv)
+	ks.Crypto.CipherText = hex.EncodeToString(cipherText)
+	ks.Crypto.MAC = hex.EncodeToString(keystoreMAC(derivedKey, cipherText, iv))
+
+	id := generateRandomBytes(16)
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	ks.ID = fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+	ks.Version = 3
+	return json.Marshal(ks)
+}
+
+// DecryptPrivateKey returns the private key of a keystore encrypted with passphrase.
+func (z *Zillean) DecryptPrivateKey(keystore []byte, passphrase string) (string, error) {
+	var ks Keystore
+	if err := json.Unmarshal(keystore, &ks); err != nil {
+		return "", fmt.Errorf("invalid keystore: %v", err)
+	}
+	if ks.Crypto.Cipher != keystoreCipher {
+		return "", fmt.Errorf("unsupported cipher %s", ks.Crypto.Cipher)
+	}
+	iv, err := hex.DecodeString(ks.Crypto.CipherParams.IV)
+	if err != nil {
+		return "", errors.New("invalid keystore iv")
+	}
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return "", errors.New("invalid keystore ciphertext")
+	}
+	mac, err := hex.DecodeString(ks.Crypto.MAC)
+	if err != nil {
+		return "", errors.New("invalid keystore mac")
+	}
+
+	derivedKey, err := deriveKey(passphrase, ks.Crypto.KDF, ks.Crypto.KDFParams)
+	if err != nil {
+		return "", err
+	}
+	if !hmac.Equal(keystoreMAC(derivedKey, cipherText, iv), mac) {
+		return "", errors.New("wrong passphrase")
+	}
+	key, err := aesCTR(derivedKey[:16], iv, cipherText)
+	if err != nil {
+		return "", err
+	}
+	if len(key) != 32 {
+		return "", fmt.Errorf("invalid keystore private key of %d bytes", len(key))
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func deriveKey(passphrase, kdf string, params KeystoreKDFParams) ([]byte, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, errors.New("invalid keystore salt")
+	}
+	if params.DKLen < 32 || params.DKLen > maxKeystoreDKLen {
+		return nil, errors.New("invalid keystore dklen")
+	}
+	switch kdf {
+	case KDFPBKDF2:
+		if params.C < 1 || params.C > maxKeystoreC {
+			return nil, fmt.Errorf("unsupported keystore c %d", params.C)
+		}
+		return pbkdf2.Key([]byte(passphrase), salt, params.C, params.DKLen, sha256.New), nil
+	case KDFScrypt:
+		if params.N > maxKeystoreN || params.R < 1 || params.R > maxKeystoreR || params.P < 1 || params.P > maxKeystoreP {
+			return nil, fmt.Errorf("unsupported keystore scrypt params n %d, r %d, p %d", params.N, params.R, params.P)
+		}
+		return scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+	}
+	return nil, fmt.Errorf("unsupported kdf %s", kdf)
+}
+
+func aesCTR(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, errors.New("invalid keystore iv")
+	}
+	out := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(out, data)
+	return out, nil
+}
+
+// keystoreMAC returns the MAC of a keystore, which authenticates the passphrase and the ciphertext.
+func keystoreMAC(derivedKey, cipherText, iv []byte) []byte {
+	mac := hmac.New(sha256.New, derivedKey)
+	mac.Write(derivedKey[16:32])
+	mac.Write(cipherText)
+	mac.Write(iv)
+	mac.Write([]byte(keystoreCipher))
+	return mac.Sum(nil)
+}
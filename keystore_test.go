@@ -0,0 +1,85 @@
+package zillean
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testKeystores are keystores of testVectors[0].privateKey encrypted with "zillean keystore". They were built with
+// the Node.js crypto module following encryptPrivateKey of the Zilliqa JavaScript library, with fixed salts and IVs.
+var testKeystores = map[string]string{
+	KDFPBKDF2: `{"address":"0x4BAF5faDA8e5Db92C3d3242618c5B47133AE003C","crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"74ca1a04c3d5b587add61ec918b88591a8cf0e18d4033c8d5806afa5a3236723","kdf":"pbkdf2","kdfparams":{"salt":"5a1f3c9e7b2d4f6081a3c5e7092b4d6f8a1c3e5079b2d4f6180a3c5e7f9b2d4e","c":262144,"dklen":32},"mac":"97430fef5f2667764fdf4771f4d783fa0cfe0d839f777ebe6bc85a6f7f0dd6bf"},"id":"0d9e8f4c-3b2a-4c1d-9e8f-7a6b5c4d3e2f","version":3}`,
+	KDFScrypt: `{"address":"0x4BAF5faDA8e5Db92C3d3242618c5B47133AE003C","crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"b5a1d93c0e7f2a4b6c8d9e0f1a2b3c4d"},"ciphertext":"26b725c39f56bec5216c18ffb198c5b589834191c8742576ec094ec36effdfad","kdf":"scrypt","kdfparams":{"salt":"2c4e6a8f0b1d3f5a7c9e1b3d5f7a9c0e2b4d6f8a1c3e5a7b9d0f2e4c6a8b0d1f","n":8192,"r":8,"p":1,"dklen":32},"mac":"4801fddb630ba6f8759b76d5bc265fac61524251e9ee1202aa83baa60c297cc9"},"id":"0d9e8f4c-3b2a-4c1d-9e8f-7a6b5c4d3e2f","version":3}`,
+}
+
+func TestZillean_DecryptPrivateKey(t *testing.T) {
+	Convey("decrypts keystores in the format of the Zilliqa JavaScript library", t, func() {
+		z := NewZillean(localNet)
+		for _, kdf := range []string{KDFPBKDF2, KDFScrypt} {
+			privateKey, err := z.DecryptPrivateKey([]byte(testKeystores[kdf]), "zillean keystore")
+			So(err, ShouldBeNil)
+			So(privateKey, ShouldEqual, testVectors[0].privateKey)
+		}
+	})
+
+	Convey("returns an error for key derivation parameters above the limits", t, func() {
+		z := NewZillean(localNet)
+		keystore := strings.Replace(testKeystores[KDFPBKDF2], `"c":262144`, `"c":1000000000`, 1)
+		_, err := z.DecryptPrivateKey([]byte(keystore), "zillean keystore")
+		So(err, ShouldBeError, "unsupported keystore c 1000000000")
+		keystore = strings.Replace(testKeystores[KDFScrypt], `"n":8192`, `"n":1073741824`, 1)
+		_, err = z.DecryptPrivateKey([]byte(keystore), "zillean keystore")
+		So(err, ShouldBeError, "unsupported keystore scrypt params n 1073741824, r 8, p 1")
+	})
+
+	Convey("returns an error if the private key is not 32 bytes long", t, func() {
+		params := KeystoreKDFParams{Salt: "00", C: 1, DKLen: 32}
+		derivedKey, err := deriveKey("passphrase", KDFPBKDF2, params)
+		So(err, ShouldBeNil)
+		iv, cipherText := make([]byte, 16), make([]byte, 31)
+		var ks Keystore
+		ks.Crypto.Cipher = keystoreCipher
+		ks.Crypto.CipherParams.IV = hex.EncodeToString(iv)
+		ks.Crypto.CipherText = hex.EncodeToString(cipherText)
+		ks.Crypto.KDF = KDFPBKDF2
+		ks.Crypto.KDFParams = params
+		ks.Crypto.MAC = hex.EncodeToString(keystoreMAC(derivedKey, cipherText, iv))
+		keystore, _ := json.Marshal(ks)
+
+		_, err = NewZillean(localNet).DecryptPrivateKey(keystore, "passphrase")
+		So(err, ShouldBeError, "invalid keystore private key of 31 bytes")
+	})
+}
+
+func TestZillean_EncryptPrivateKey(t *testing.T) {
+	Convey("encrypts a private key into a keystore which decrypts with the passphrase", t, func() {
+		z := NewZillean(localNet)
+		for _, kdf := range []string{KDFPBKDF2, KDFScrypt} {
+			keystore, err := z.EncryptPrivateKey(testVectors[0].privateKey, "passphrase", kdf)
+			So(err, ShouldBeNil)
+
+			var ks Keystore
+			So(json.Unmarshal(keystore, &ks), ShouldBeNil)
+			So(ks.Address, ShouldEqual, "0x4BAF5faDA8e5Db92C3d3242618c5B47133AE003C")
+			So(ks.Crypto.KDF, ShouldEqual, kdf)
+			So(ks.Version, ShouldEqual, 3)
+			So(ks.ID, ShouldHaveLength, 36)
+
+			privateKey, err := z.DecryptPrivateKey(keystore, "passphrase")
+			So(err, ShouldBeNil)
+			So(privateKey, ShouldEqual, testVectors[0].privateKey)
+
+			_, err = z.DecryptPrivateKey(keystore, "wrong")
+			So(err.Error(), ShouldEqual, "wrong passphrase")
+		}
+
+		_, err := z.EncryptPrivateKey(testVectors[0].privateKey, "passphrase", "argon2")
+		So(err.Error(), ShouldEqual, "unsupported kdf argon2")
+		_, err = z.DecryptPrivateKey([]byte(`{"crypto":{"cipher":"aes-256-gcm"}}`), "passphrase")
+		So(err.Error(), ShouldEqual, "unsupported cipher aes-256-gcm")
+	})
+}
@@ -0,0 +1,82 @@
+package zillean
+
+import (
+	"context"
+	"fmt"
+)
+
+// MicroBlockTransactions describes the transactions of a microblock of a TX block.
+type MicroBlockTransactions struct {
+	MicroBlockHash Hash
+	// ShardID is the shard which created the microblock. The microblock of the DS committee has the number of
+	// shards as its ID.
+	ShardID int64
+	TxIDs   []string
+	// Transactions are the transactions of TxIDs in the same order. They are only fetched by
+	// FetchMicroBlockTransactions.
+	Transactions []*Transaction
+}
+
+// GetMicroBlockTransactions returns the transaction IDs of a TX block grouped by microblock, in the order of
+// the MicroBlockInfos of the block.
+func (r *RPC) GetMicroBlockTransactions(blockNumber string) ([]*MicroBlockTransactions, error) {
+	block, err := r.GetTxBlock(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	microBlocks := make([]*MicroBlockTransactions, len(block.Body.MicroBlockInfos))
+	for i, info := range block.Body.MicroBlockInfos {
+		microBlocks[i] = &MicroBlockTransactions{MicroBlockHash: info.MicroBlockHash, ShardID: info.MicroBlockShardID}
+	}
+	// The node returns an error instead of empty lists for a block without transactions.
+	if block.Header.NumTxns == 0 {
+		return microBlocks, nil
+	}
+
+	txIDs, err := r.GetTransactionsForTxBlock(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(txIDs) != len(microBlocks) {
+		return nil, fmt.Errorf("block %s has %d microblocks, but transactions for %d", blockNumber, len(microBlocks), len(txIDs))
+	}
+	for i, ids := range txIDs {
+		microBlocks[i].TxIDs = ids
+	}
+	return microBlocks, nil
+}
+
+// GetShardTransactionCounts returns the number of transactions in a TX block by the ID of the shard which
+// created them.
+func (r *RPC) GetShardTransactionCounts(blockNumber string) (map[int64]int, error) {
+	microBlocks, err := r.GetMicroBlockTransactions(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int64]int, len(microBlocks))
+	for _, mb := range microBlocks {
+		counts[mb.ShardID] += len(mb.TxIDs)
+	}
+	return counts, nil
+}
+
+// FetchMicroBlockTransactions returns the transactions of a TX block grouped by microblock as
+// GetMicroBlockTransactions does, with the transactions fetched with up to concurrency requests at once.
+func (r *RPC) FetchMicroBlockTransactions(ctx context.Context, blockNumber string, concurrency int) ([]*MicroBlockTransactions, error) {
+	microBlocks, err := r.GetMicroBlockTransactions(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	var txIDs []string
+	for _, mb := range microBlocks {
+		txIDs = append(txIDs, mb.TxIDs...)
+	}
+	txs, err := fetchTransactions(ctx, r, txIDs, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	for _, mb := range microBlocks {
+		mb.Transactions, txs = txs[:len(mb.TxIDs):len(mb.TxIDs)], txs[len(mb.TxIDs):]
+	}
+	return microBlocks, nil
+}
@@ -0,0 +1,139 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newShardedNode returns a node stand-in whose TX block 1 has microblocks of shards 0 and 1 and of the DS
+// committee, and whose block 2 is empty. It records the largest number of concurrent GetTransaction calls.
+func newShardedNode(maxInFlight *int) func(method string, params json.RawMessage) (interface{}, string) {
+	var mu sync.Mutex
+	inFlight := 0
+	microBlocks := []map[string]interface{}{
+		{"MicroBlockHash": strings.Repeat("01", 32), "MicroBlockShardId": 0},
+		{"MicroBlockHash": strings.Repeat("02", 32), "MicroBlockShardId": 1},
+		{"MicroBlockHash": strings.Repeat("03", 32), "MicroBlockShardId": 2},
+	}
+
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		var args []string
+		json.Unmarshal(params, &args)
+		switch method {
+		case "GetTxBlock":
+			if args[0] == "2" {
+				return map[string]interface{}{"body": map[string]interface{}{"MicroBlockInfos": microBlocks[:1]},
+					"header": map[string]interface{}{"BlockNum": "2", "NumTxns": 0}}, ""
+			}
+			return map[string]interface{}{"body": map[string]interface{}{"MicroBlockInfos": microBlocks},
+				"header": map[string]interface{}{"BlockNum": "1", "NumTxns": 4}}, ""
+		case "GetTransactionsForTxBlock":
+			if args[0] == "2" {
+				return nil, "TxBlock has no transactions"
+			}
+			return [][]string{{"a1", "a2"}, {}, {"c1", "c2"}}, ""
+		case "GetTransaction":
+			mu.Lock()
+			inFlight++
+			if inFlight > *maxInFlight {
+				*maxInFlight = inFlight
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+			return map[string]interface{}{"ID": args[0], "amount": "1"}, ""
+		}
+		return nil, "METHOD_NOT_FOUND: The method being requested is not available on this server"
+	}
+}
+
+func TestRPC_GetMicroBlockTransactions(t *testing.T) {
+	Convey("returns the transaction IDs of a TX block grouped by microblock", t, func() {
+		var maxInFlight int
+		node := newStubNode(newShardedNode(&maxInFlight))
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).GetMicroBlockTransactions("1")
+		So(err, ShouldBeNil)
+		So(result, ShouldHaveLength, 3)
+		So(result[0].MicroBlockHash.String(), ShouldEqual, strings.Repeat("01", 32))
+		So(result[0].ShardID, ShouldEqual, 0)
+		So(result[0].TxIDs, ShouldResemble, []string{"a1", "a2"})
+		So(result[1].TxIDs, ShouldBeEmpty)
+		So(result[2].ShardID, ShouldEqual, 2)
+		So(result[2].TxIDs, ShouldResemble, []string{"c1", "c2"})
+		So(result[2].Transactions, ShouldBeNil)
+	})
+
+	Convey("returns empty microblocks for a block without transactions", t, func() {
+		var maxInFlight int
+		node := newStubNode(newShardedNode(&maxInFlight))
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).GetMicroBlockTransactions("2")
+		So(err, ShouldBeNil)
+		So(result, ShouldHaveLength, 1)
+		So(result[0].TxIDs, ShouldBeEmpty)
+	})
+
+	Convey("returns an error if the transactions do not match the microblocks", t, func() {
+		node := newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+			if method == "GetTxBlock" {
+				return map[string]interface{}{"header": map[string]interface{}{"BlockNum": "1", "NumTxns": 1}}, ""
+			}
+			return [][]string{{"a1"}}, ""
+		})
+		defer node.Close()
+
+		_, err := NewRPC(node.URL).GetMicroBlockTransactions("1")
+		So(err, ShouldBeError, "block 1 has 0 microblocks, but transactions for 1")
+	})
+}
+
+func TestRPC_GetShardTransactionCounts(t *testing.T) {
+	Convey("returns the number of transactions by shard", t, func() {
+		var maxInFlight int
+		node := newStubNode(newShardedNode(&maxInFlight))
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).GetShardTransactionCounts("1")
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, map[int64]int{0: 2, 1: 0, 2: 2})
+	})
+}
+
+func TestRPC_FetchMicroBlockTransactions(t *testing.T) {
+	Convey("returns the transactions of a TX block grouped by microblock", t, func() {
+		var maxInFlight int
+		node := newStubNode(newShardedNode(&maxInFlight))
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).FetchMicroBlockTransactions(context.Background(), "1", 2)
+		So(err, ShouldBeNil)
+		So(result, ShouldHaveLength, 3)
+		So(result[0].Transactions, ShouldHaveLength, 2)
+		So(result[0].Transactions[1].ID, ShouldEqual, "a2")
+		So(result[1].Transactions, ShouldBeEmpty)
+		So(result[2].Transactions[0].ID, ShouldEqual, "c1")
+		So(maxInFlight, ShouldBeBetweenOrEqual, 1, 2)
+	})
+
+	Convey("returns an error if ctx is done", t, func() {
+		var maxInFlight int
+		node := newStubNode(newShardedNode(&maxInFlight))
+		defer node.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := NewRPC(node.URL).FetchMicroBlockTransactions(ctx, "1", 2)
+		So(err, ShouldEqual, context.Canceled)
+	})
+}
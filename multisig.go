@@ -0,0 +1,370 @@
+package zillean
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// MultisigWallet represents a multi-signature wallet contract, the standard Zilliqa wallet which sends funds
+// once the required number of its owners signed the transaction.
+type MultisigWallet struct {
+	Address string
+	zil     *Zillean
+}
+
+// MultisigTransaction describes a pending transaction of a MultisigWallet. Signers are the lowercase
+// 0x-prefixed addresses of the owners who signed it, in ascending order.
+type MultisigTransaction struct {
+	ID        uint32
+	Recipient string
+	Amount    *big.Int
+	Tag       string
+	Signers   []string
+}
+
+// MultisigProposal coordinates a transaction of a MultisigWallet across its owners, who may approve it from
+// different processes by its ID. Each owner signs it once, and the owner whose signature completes the required
+// number executes it.
+type MultisigProposal struct {
+	ID     uint32
+	wallet *MultisigWallet
+}
+
+// MultisigProposalStatus describes the progress of a MultisigProposal. Transaction is nil once it was executed.
+type MultisigProposalStatus struct {
+	Transaction        *MultisigTransaction
+	RequiredSignatures uint32
+	Executed           bool
+}
+
+// DeployMultisigWallet deploys the code of the standard multisig wallet contract from the account of a private key,
+// with owners of which requiredSignatures must sign a transaction, and returns a binding to the new wallet.
+func DeployMultisigWallet(ctx context.Context, zil *Zillean, privateKey, code string, owners []string, requiredSignatures uint32, opts *TxOptions) (*MultisigWallet, *DeployResult, error) {
+	if len(owners) == 0 {
+		return nil, nil, errors.New("missing owners")
+	}
+	if requiredSignatures == 0 || int(requiredSignatures) > len(owners) {
+		return nil, nil, fmt.Errorf("required signatures must be between 1 and %d", len(owners))
+	}
+	seen := map[string]bool{}
+	ownerList := make([]Value, len(owners))
+	for i, owner := range owners {
+		address := strings.ToLower(strings.TrimPrefix(owner, "0x"))
+		if seen[address] {
+			return nil, nil, fmt.Errorf("duplicate owner %s", owner)
+		}
+		seen[address] = true
+		ownerList[i] = ByStr20(owner)
+	}
+
+	result, err := zil.DeployContract(ctx, privateKey, code, []Param{
+		{"owners_list", List("ByStr20", ownerList...)},
+		{"required_signatures", Uint32(requiredSignatures)},
+	}, opts)
+	if err != nil {
+		return nil, result, err
+	}
+	return NewMultisigWallet(result.ContractAddress, zil), result, nil
+}
+
+// NewMultisigWallet returns a new MultisigWallet bound to a wallet contract address.
+func NewMultisigWallet(address string, zil *Zillean) *MultisigWallet {
+	return &MultisigWallet{Address: strings.ToLower(strings.TrimPrefix(address, "0x")), zil: zil}
+}
+
+// Owners returns the lowercase 0x-prefixed addresses of the owners of the wallet, in ascending order.
+func (w *MultisigWallet) Owners() ([]string, error) {
+	var owners map[string]bool
+	if err := w.zil.RPC.getStateVariable(w.Address, "owners", "Map (ByStr20) (Bool)", &owners); err != nil {
+		return nil, err
+	}
+	return trueKeys(owners), nil
+}
+
+// RequiredSignatures returns the number of owners who must sign a transaction before it is executed.
+func (w *MultisigWallet) RequiredSignatures() (uint32, error) {
+	init, err := w.zil.RPC.GetSmartContractInit(w.Address)
+	if err != nil {
+		return 0, err
+	}
+	var params struct {
+		RequiredSignatures *uint32 `scilla:"required_signatures"`
+	}
+	if err := Unmarshal(init, &params); err != nil {
+		return 0, err
+	}
+	if params.RequiredSignatures == nil {
+		return 0, fmt.Errorf("contract %s has no required_signatures", w.Address)
+	}
+	return *params.RequiredSignatures, nil
+}
+
+// PendingTransactions returns the transactions of the wallet which were submitted but not executed, in ascending
+// order of their IDs.
+func (w *MultisigWallet) PendingTransactions() ([]*MultisigTransaction, error) {
+	var transactions map[uint32]ADTValue
+	if err := w.zil.RPC.getStateVariable(w.Address, "transactions", "Map (Uint32) (Transaction)", &transactions); err != nil {
+		return nil, err
+	}
+	var signatures map[uint32]map[string]bool
+	if err := w.zil.RPC.getStateVariable(w.Address, "signatures", "Map (Uint32) (Map (ByStr20) (Bool))", &signatures); err != nil {
+		return nil, err
+	}
+
+	pending := make([]*MultisigTransaction, 0, len(transactions))
+	for id, adt := range transactions {
+		tx, err := newMultisigTransaction(id, adt, signatures[id])
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, tx)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+	return pending, nil
+}
+
+// Transaction returns a pending transaction of the wallet. It reports false if the transaction does not exist
+// or was executed.
+func (w *MultisigWallet) Transaction(id uint32) (*MultisigTransaction, bool, error) {
+	var adt ADTValue
+	ok, err := w.zil.RPC.GetMapEntry(w.Address, "transactions", []Value{Uint32(id)}, "Transaction", &adt)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	var signatures map[string]bool
+	if _, err := w.zil.RPC.GetMapEntry(w.Address, "signatures", []Value{Uint32(id)}, "Map (ByStr20) (Bool)", &signatures); err != nil {
+		return nil, false, err
+	}
+	tx, err := newMultisigTransaction(id, adt, signatures)
+	if err != nil {
+		return nil, false, err
+	}
+	return tx, true, nil
+}
+
+// TransactionCount returns the number of transactions submitted to the wallet, which is the ID of the next one.
+func (w *MultisigWallet) TransactionCount() (uint32, error) {
+	var count uint32
+	if err := w.zil.RPC.getStateVariable(w.Address, "transactionCount", "Uint32", &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SubmitTransaction submits a transaction sending amount (in Qa) to a recipient from the account of an owner,
+// and returns its ID. tag is the transition called on a contract recipient, e.g. AddFunds.
+// As with CallContract, a confirmed call which failed in the contract is not an error, and the ID is then zero.
+func (w *MultisigWallet) SubmitTransaction(ctx context.Context, privateKey, recipient string, amount *big.Int, tag string, opts *TxOptions) (uint32, *ContractResult, error) {
+	result, err := w.call(ctx, privateKey, "SubmitTransaction", "0", opts,
+		Param{"recipient", ByStr20(recipient)},
+		Param{"amount", Uint128(amount)},
+		Param{"tag", String(tag)},
+	)
+	if err != nil || !result.Success {
+		return 0, result, err
+	}
+	for _, log := range result.Receipt.EventLogs {
+		if strings.ToLower(strings.TrimPrefix(log.Address, "0x")) != w.Address {
+			continue
+		}
+		if param, ok := log.Param("transactionId"); ok {
+			var id uint32
+			if err := param.Unmarshal(&id); err != nil {
+				return 0, result, fmt.Errorf("%s: %v", log.EventName, err)
+			}
+			return id, result, nil
+		}
+	}
+	return 0, result, errors.New("wallet emitted no transaction ID")
+}
+
+// SignTransaction signs a pending transaction from the account of an owner.
+func (w *MultisigWallet) SignTransaction(ctx context.Context, privateKey string, id uint32, opts *TxOptions) (*ContractResult, error) {
+	return w.call(ctx, privateKey, "SignTransaction", "0", opts, Param{"transactionId", Uint32(id)})
+}
+
+// RevokeSignature revokes the signature of an owner on a pending transaction.
+func (w *MultisigWallet) RevokeSignature(ctx context.Context, privateKey string, id uint32, opts *TxOptions) (*ContractResult, error) {
+	return w.call(ctx, privateKey, "RevokeSignature", "0", opts, Param{"transactionId", Uint32(id)})
+}
+
+// ExecuteTransaction executes a pending transaction which has the required signatures, from the account of
+// an owner or of the recipient.
+func (w *MultisigWallet) ExecuteTransaction(ctx context.Context, privateKey string, id uint32, opts *TxOptions) (*ContractResult, error) {
+	return w.call(ctx, privateKey, "ExecuteTransaction", "0", opts, Param{"transactionId", Uint32(id)})
+}
+
+// AddFunds sends amount (in Qa) to the wallet from the account of a private key.
+func (w *MultisigWallet) AddFunds(ctx context.Context, privateKey, amount string, opts *TxOptions) (*ContractResult, error) {
+	return w.call(ctx, privateKey, "AddFunds", amount, opts)
+}
+
+// Propose submits a transaction from the account of an owner and approves it as that owner.
+// If the transaction was submitted but not approved, the proposal is returned along with the error.
+func (w *MultisigWallet) Propose(ctx context.Context, privateKey, recipient string, amount *big.Int, tag string, opts *TxOptions) (*MultisigProposal, error) {
+	id, result, err := w.SubmitTransaction(ctx, privateKey, recipient, amount, tag, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, errors.New("SubmitTransaction failed")
+	}
+	proposal := w.Proposal(id)
+	if _, err := proposal.Approve(ctx, privateKey, opts); err != nil {
+		return proposal, err
+	}
+	return proposal, nil
+}
+
+// Proposal returns the MultisigProposal of a submitted transaction.
+func (w *MultisigWallet) Proposal(id uint32) *MultisigProposal {
+	return &MultisigProposal{ID: id, wallet: w}
+}
+
+// Status returns the progress of the proposal.
+func (p *MultisigProposal) Status() (*MultisigProposalStatus, error) {
+	required, err := p.wallet.RequiredSignatures()
+	if err != nil {
+		return nil, err
+	}
+	tx, ok, err := p.wallet.Transaction(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &MultisigProposalStatus{Transaction: tx, RequiredSignatures: required}, nil
+	}
+
+	count, err := p.wallet.TransactionCount()
+	if err != nil {
+		return nil, err
+	}
+	if p.ID >= count {
+		return nil, fmt.Errorf("transaction %d does not exist", p.ID)
+	}
+	return &MultisigProposalStatus{RequiredSignatures: required, Executed: true}, nil
+}
+
+// Approve signs the proposal as the owner of a private key unless the owner already signed it, and executes it
+// once it has the required signatures. It returns the status after these steps, and does nothing if the proposal
+// was executed.
+func (p *MultisigProposal) Approve(ctx context.Context, privateKey string, opts *TxOptions) (*MultisigProposalStatus, error) {
+	owner, err := p.wallet.zil.GetAddressFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	status, err := p.Status()
+	if err != nil || status.Executed {
+		return status, err
+	}
+
+	if !status.Transaction.SignedBy(owner) {
+		if err := p.step(ctx, "SignTransaction", privateKey, opts); err != nil {
+			return nil, err
+		}
+		if status, err = p.Status(); err != nil || status.Executed {
+			return status, err
+		}
+		if !status.Transaction.SignedBy(owner) {
+			return nil, fmt.Errorf("0x%s did not sign transaction %d", strings.ToLower(owner), p.ID)
+		}
+	}
+	if len(status.Transaction.Signers) < int(status.RequiredSignatures) {
+		return status, nil
+	}
+
+	if err := p.step(ctx, "ExecuteTransaction", privateKey, opts); err != nil {
+		return nil, err
+	}
+	if status, err = p.Status(); err != nil {
+		return nil, err
+	}
+	if !status.Executed {
+		return nil, fmt.Errorf("transaction %d was not executed", p.ID)
+	}
+	return status, nil
+}
+
+// Revoke revokes the signature of the owner of a private key on the proposal, if the owner signed it.
+func (p *MultisigProposal) Revoke(ctx context.Context, privateKey string, opts *TxOptions) (*MultisigProposalStatus, error) {
+	owner, err := p.wallet.zil.GetAddressFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	status, err := p.Status()
+	if err != nil {
+		return nil, err
+	}
+	if status.Executed {
+		return nil, fmt.Errorf("transaction %d was executed", p.ID)
+	}
+	if !status.Transaction.SignedBy(owner) {
+		return status, nil
+	}
+	if err := p.step(ctx, "RevokeSignature", privateKey, opts); err != nil {
+		return nil, err
+	}
+	return p.Status()
+}
+
+// step calls a transition of the wallet on the proposal, which is an error if it failed in the contract.
+func (p *MultisigProposal) step(ctx context.Context, transition, privateKey string, opts *TxOptions) error {
+	result, err := p.wallet.call(ctx, privateKey, transition, "0", opts, Param{"transactionId", Uint32(p.ID)})
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("%s of transaction %d failed", transition, p.ID)
+	}
+	return nil
+}
+
+// SignedBy checks whether an owner signed the transaction.
+func (t *MultisigTransaction) SignedBy(owner string) bool {
+	owner = "0x" + strings.ToLower(strings.TrimPrefix(owner, "0x"))
+	for _, signer := range t.Signers {
+		if signer == owner {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *MultisigWallet) call(ctx context.Context, privateKey, transition, amount string, opts *TxOptions, params ...Param) (*ContractResult, error) {
+	return w.zil.CallContract(ctx, privateKey, w.Address, transition, params, amount, opts)
+}
+
+// newMultisigTransaction returns a pending transaction from its Trans value in the wallet state.
+func newMultisigTransaction(id uint32, adt ADTValue, signatures map[string]bool) (*MultisigTransaction, error) {
+	if adt.Constructor != "Trans" || len(adt.Arguments) != 3 {
+		return nil, fmt.Errorf("transaction %d: unsupported transaction %s", id, adt.Constructor)
+	}
+	tx := &MultisigTransaction{ID: id, Signers: trueKeys(signatures)}
+	if err := UnmarshalValue("ByStr20", adt.Arguments[0], &tx.Recipient); err != nil {
+		return nil, fmt.Errorf("transaction %d: %v", id, err)
+	}
+	if err := UnmarshalValue("Uint128", adt.Arguments[1], &tx.Amount); err != nil {
+		return nil, fmt.Errorf("transaction %d: %v", id, err)
+	}
+	if err := UnmarshalValue("String", adt.Arguments[2], &tx.Tag); err != nil {
+		return nil, fmt.Errorf("transaction %d: %v", id, err)
+	}
+	tx.Recipient = strings.ToLower(tx.Recipient)
+	return tx, nil
+}
+
+// trueKeys returns the lowercase keys of a map of Bool values whose value is true, in ascending order.
+func trueKeys(m map[string]bool) []string {
+	var keys []string
+	for key, ok := range m {
+		if ok {
+			keys = append(keys, strings.ToLower(key))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
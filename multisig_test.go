@@ -0,0 +1,287 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const multisigAddress = "6c1169e8a77d34d6d615862db5f62f0a9791cb9f"
+
+// newMultisigNode returns a node stand-in which runs a multisig wallet at multisigAddress owned by the
+// accounts of testVectors 0 to 2, of which 2 must sign. It records the transitions called on the wallet.
+func newMultisigNode(calls *[]string) func(method string, params json.RawMessage) (interface{}, string) {
+	var mu sync.Mutex
+	owners := map[string]bool{}
+	for _, v := range testVectors[:3] {
+		owners["0x"+v.address] = true
+	}
+	var count uint32
+	transactions := map[string]interface{}{}
+	signatures := map[string]map[string]interface{}{}
+	var receipt map[string]interface{}
+	wallet := newWalletNode(0, func(RawTransaction) {})
+	zil := NewZillean("")
+
+	boolValue := map[string]interface{}{"constructor": "True", "argtypes": []string{}, "arguments": []string{}}
+	// confirmed returns a receipt with an event of the wallet on transactionId id, or a failed one if name is empty.
+	confirmed := func(name string, id string) map[string]interface{} {
+		receipt := map[string]interface{}{"cumulative_gas": "1", "epoch_num": "68317", "success": name != ""}
+		if name != "" {
+			receipt["event_logs"] = []interface{}{map[string]interface{}{"_eventname": name, "address": "0x" + multisigAddress,
+				"params": []map[string]string{{"vname": "transactionId", "type": "Uint32", "value": id}}}}
+		}
+		return receipt
+	}
+
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch method {
+		case "CreateTransaction":
+			var rawTxs []RawTransaction
+			json.Unmarshal(params, &rawTxs)
+			var msg struct {
+				Tag    string `json:"_tag"`
+				Params []struct {
+					Vname string `json:"vname"`
+					Value string `json:"value"`
+				} `json:"params"`
+			}
+			json.Unmarshal([]byte(rawTxs[0].Data), &msg)
+			*calls = append(*calls, msg.Tag)
+			sender, _ := zil.GetAddressFromPublicKey(rawTxs[0].PubKey)
+			sender = "0x" + strings.ToLower(sender)
+			args := map[string]string{}
+			for _, p := range msg.Params {
+				args[p.Vname] = p.Value
+			}
+
+			id := args["transactionId"]
+			receipt = confirmed("", id)
+			switch {
+			case !owners[sender]:
+			case msg.Tag == "SubmitTransaction":
+				id = fmt.Sprint(count)
+				count++
+				transactions[id] = map[string]interface{}{"constructor": "Trans", "argtypes": []string{},
+					"arguments": []string{args["recipient"], args["amount"], args["tag"]}}
+				receipt = confirmed("Transaction created", id)
+			case transactions[id] == nil:
+			case msg.Tag == "SignTransaction":
+				if signatures[id] == nil {
+					signatures[id] = map[string]interface{}{}
+				}
+				signatures[id][sender] = boolValue
+				receipt = confirmed("Transaction signed", id)
+			case msg.Tag == "RevokeSignature":
+				delete(signatures[id], sender)
+				receipt = confirmed("Signature revoked", id)
+			case msg.Tag == "ExecuteTransaction" && len(signatures[id]) >= 2:
+				delete(transactions, id)
+				delete(signatures, id)
+				receipt = confirmed("Transaction executed", id)
+			}
+		case "GetTransaction":
+			return map[string]interface{}{"ID": "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5", "receipt": receipt}, ""
+		case "GetSmartContractInit":
+			return []map[string]string{{"vname": "required_signatures", "type": "Uint32", "value": "2"}}, ""
+		case "GetSmartContractSubState":
+			var args []json.RawMessage
+			json.Unmarshal(params, &args)
+			var variable string
+			var indices []string
+			json.Unmarshal(args[1], &variable)
+			json.Unmarshal(args[2], &indices)
+			switch variable {
+			case "owners":
+				entries := map[string]interface{}{}
+				for owner := range owners {
+					entries[owner] = boolValue
+				}
+				return map[string]interface{}{variable: entries}, ""
+			case "transactionCount":
+				return map[string]interface{}{variable: fmt.Sprint(count)}, ""
+			case "transactions":
+				if len(indices) == 0 {
+					return map[string]interface{}{variable: transactions}, ""
+				}
+				if transactions[indices[0]] == nil {
+					return nil, ""
+				}
+				return map[string]interface{}{variable: map[string]interface{}{indices[0]: transactions[indices[0]]}}, ""
+			case "signatures":
+				if len(indices) == 0 {
+					return map[string]interface{}{variable: signatures}, ""
+				}
+				if signatures[indices[0]] == nil {
+					return nil, ""
+				}
+				return map[string]interface{}{variable: map[string]interface{}{indices[0]: signatures[indices[0]]}}, ""
+			}
+			return nil, ""
+		}
+		return wallet(method, params)
+	}
+}
+
+func TestDeployMultisigWallet(t *testing.T) {
+	Convey("deploys the wallet with its owners and required signatures", t, func() {
+		var rawTx RawTransaction
+		node := newStubNode(newWalletNode(0, func(tx RawTransaction) { rawTx = tx }))
+		defer node.Close()
+
+		owners := []string{testVectors[0].address, "0x" + testVectors[1].address}
+		wallet, result, err := DeployMultisigWallet(context.Background(), NewZillean(node.URL), testVectors[0].privateKey, helloWorldCode, owners, 2, &TxOptions{Wait: fastWait})
+		So(err, ShouldBeNil)
+		So(result.Success, ShouldBeTrue)
+		So(wallet.Address, ShouldEqual, multisigAddress)
+		So(rawTx.Data, ShouldEqual, `[{"vname":"_scilla_version","type":"Uint32","value":"0"},`+
+			`{"vname":"owners_list","type":"List (ByStr20)","value":["0x4baf5fada8e5db92c3d3242618c5b47133ae003c","0x448261915a80cde9bde7c7a791685200d3a0bf4e"]},`+
+			`{"vname":"required_signatures","type":"Uint32","value":"2"}]`)
+	})
+
+	Convey("returns an error if the owners or the required signatures are invalid", t, func() {
+		zil := NewZillean(localNet)
+		_, _, err := DeployMultisigWallet(context.Background(), zil, testVectors[0].privateKey, helloWorldCode, nil, 1, nil)
+		So(err, ShouldBeError, "missing owners")
+
+		owners := []string{testVectors[0].address, testVectors[1].address}
+		_, _, err = DeployMultisigWallet(context.Background(), zil, testVectors[0].privateKey, helloWorldCode, owners, 3, nil)
+		So(err, ShouldBeError, "required signatures must be between 1 and 2")
+
+		owners = []string{testVectors[0].address, "0x" + strings.ToUpper(testVectors[0].address)}
+		_, _, err = DeployMultisigWallet(context.Background(), zil, testVectors[0].privateKey, helloWorldCode, owners, 1, nil)
+		So(err.Error(), ShouldStartWith, "duplicate owner")
+	})
+}
+
+func TestMultisigWallet_Transactions(t *testing.T) {
+	Convey("submits, signs and revokes transactions, and reads them from the state", t, func() {
+		var calls []string
+		node := newStubNode(newMultisigNode(&calls))
+		defer node.Close()
+		wallet := NewMultisigWallet("0x"+multisigAddress, NewZillean(node.URL))
+		opts := &TxOptions{Wait: fastWait}
+
+		owners, err := wallet.Owners()
+		So(err, ShouldBeNil)
+		So(owners, ShouldResemble, []string{"0x448261915a80cde9bde7c7a791685200d3a0bf4e", "0x4baf5fada8e5db92c3d3242618c5b47133ae003c", "0xded02fd979fc2e55c0243bd2f52df022c40ada1e"})
+		required, err := wallet.RequiredSignatures()
+		So(err, ShouldBeNil)
+		So(required, ShouldEqual, 2)
+
+		id, result, err := wallet.SubmitTransaction(context.Background(), testVectors[0].privateKey, testVectors[3].address, big.NewInt(1000), "AddFunds", opts)
+		So(err, ShouldBeNil)
+		So(result.Success, ShouldBeTrue)
+		So(id, ShouldEqual, 0)
+		id, _, err = wallet.SubmitTransaction(context.Background(), testVectors[1].privateKey, testVectors[3].address, big.NewInt(5), "", opts)
+		So(err, ShouldBeNil)
+		So(id, ShouldEqual, 1)
+
+		_, err = wallet.SignTransaction(context.Background(), testVectors[1].privateKey, 1, opts)
+		So(err, ShouldBeNil)
+		_, err = wallet.SignTransaction(context.Background(), testVectors[2].privateKey, 1, opts)
+		So(err, ShouldBeNil)
+		_, err = wallet.RevokeSignature(context.Background(), testVectors[1].privateKey, 1, opts)
+		So(err, ShouldBeNil)
+
+		pending, err := wallet.PendingTransactions()
+		So(err, ShouldBeNil)
+		So(pending, ShouldHaveLength, 2)
+		So(pending[0].ID, ShouldEqual, 0)
+		So(pending[0].Recipient, ShouldEqual, "0x"+testVectors[3].address)
+		So(pending[0].Amount.Int64(), ShouldEqual, 1000)
+		So(pending[0].Tag, ShouldEqual, "AddFunds")
+		So(pending[0].Signers, ShouldBeEmpty)
+		So(pending[1].Signers, ShouldResemble, []string{"0xded02fd979fc2e55c0243bd2f52df022c40ada1e"})
+
+		tx, ok, err := wallet.Transaction(1)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		So(tx, ShouldResemble, pending[1])
+		So(tx.SignedBy("DED02FD979FC2E55C0243BD2F52DF022C40ADA1E"), ShouldBeTrue)
+		_, ok, err = wallet.Transaction(2)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+
+		_, err = wallet.ExecuteTransaction(context.Background(), testVectors[0].privateKey, 1, opts)
+		So(err, ShouldBeNil)
+		So(calls, ShouldResemble, []string{"SubmitTransaction", "SubmitTransaction", "SignTransaction", "SignTransaction", "RevokeSignature", "ExecuteTransaction"})
+	})
+}
+
+func TestMultisigProposal(t *testing.T) {
+	Convey("coordinates a transaction across owners until it is executed", t, func() {
+		var calls []string
+		node := newStubNode(newMultisigNode(&calls))
+		defer node.Close()
+		wallet := NewMultisigWallet(multisigAddress, NewZillean(node.URL))
+		opts := &TxOptions{Wait: fastWait}
+
+		proposal, err := wallet.Propose(context.Background(), testVectors[0].privateKey, testVectors[3].address, big.NewInt(1000), "", opts)
+		So(err, ShouldBeNil)
+		So(proposal.ID, ShouldEqual, 0)
+		So(calls, ShouldResemble, []string{"SubmitTransaction", "SignTransaction"})
+
+		status, err := wallet.Proposal(0).Approve(context.Background(), testVectors[0].privateKey, opts)
+		So(err, ShouldBeNil)
+		So(status.Executed, ShouldBeFalse)
+		So(status.RequiredSignatures, ShouldEqual, 2)
+		So(status.Transaction.Signers, ShouldResemble, []string{"0x4baf5fada8e5db92c3d3242618c5b47133ae003c"})
+		So(calls, ShouldHaveLength, 2)
+
+		status, err = wallet.Proposal(0).Approve(context.Background(), testVectors[1].privateKey, opts)
+		So(err, ShouldBeNil)
+		So(status.Executed, ShouldBeTrue)
+		So(status.Transaction, ShouldBeNil)
+		So(calls[2:], ShouldResemble, []string{"SignTransaction", "ExecuteTransaction"})
+
+		status, err = proposal.Approve(context.Background(), testVectors[2].privateKey, opts)
+		So(err, ShouldBeNil)
+		So(status.Executed, ShouldBeTrue)
+		So(calls, ShouldHaveLength, 4)
+
+		_, err = wallet.Proposal(1).Status()
+		So(err, ShouldBeError, "transaction 1 does not exist")
+	})
+
+	Convey("revokes the signature of an owner", t, func() {
+		var calls []string
+		node := newStubNode(newMultisigNode(&calls))
+		defer node.Close()
+		wallet := NewMultisigWallet(multisigAddress, NewZillean(node.URL))
+		opts := &TxOptions{Wait: fastWait}
+
+		proposal, err := wallet.Propose(context.Background(), testVectors[0].privateKey, testVectors[3].address, big.NewInt(1000), "", opts)
+		So(err, ShouldBeNil)
+		status, err := proposal.Revoke(context.Background(), testVectors[0].privateKey, opts)
+		So(err, ShouldBeNil)
+		So(status.Transaction.Signers, ShouldBeEmpty)
+		status, err = proposal.Revoke(context.Background(), testVectors[0].privateKey, opts)
+		So(err, ShouldBeNil)
+		So(calls, ShouldResemble, []string{"SubmitTransaction", "SignTransaction", "RevokeSignature"})
+	})
+
+	Convey("returns an error if an account which is not an owner approves", t, func() {
+		var calls []string
+		node := newStubNode(newMultisigNode(&calls))
+		defer node.Close()
+		wallet := NewMultisigWallet(multisigAddress, NewZillean(node.URL))
+		opts := &TxOptions{Wait: fastWait}
+
+		_, err := wallet.Propose(context.Background(), testVectors[3].privateKey, testVectors[0].address, big.NewInt(1000), "", opts)
+		So(err, ShouldBeError, "SubmitTransaction failed")
+
+		proposal, err := wallet.Propose(context.Background(), testVectors[0].privateKey, testVectors[3].address, big.NewInt(1000), "", opts)
+		So(err, ShouldBeNil)
+		_, err = proposal.Approve(context.Background(), testVectors[3].privateKey, opts)
+		So(err, ShouldBeError, "SignTransaction of transaction 0 failed")
+	})
+}
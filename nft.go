@@ -0,0 +1,379 @@
+package zillean
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// NFTStandard represents the standard of a non-fungible token contract.
+type NFTStandard int
+
+// Supported non-fungible token standards.
+const (
+	ZRC6 NFTStandard = iota
+	ZRC1
+)
+
+func (s NFTStandard) String() string {
+	if s == ZRC1 {
+		return "ZRC-1"
+	}
+	return "ZRC-6"
+}
+
+// NFTEventKind represents the kind of a standard event of a non-fungible token contract.
+type NFTEventKind string
+
+// Kinds of NFT events, which are named after the ZRC-6 events. ZRC-1 events are mapped to the same kinds.
+const (
+	NFTMint           NFTEventKind = "Mint"
+	NFTBurn           NFTEventKind = "Burn"
+	NFTTransfer       NFTEventKind = "TransferFrom"
+	NFTSetSpender     NFTEventKind = "SetSpender"
+	NFTAddOperator    NFTEventKind = "AddOperator"
+	NFTRemoveOperator NFTEventKind = "RemoveOperator"
+	NFTSetBaseURI     NFTEventKind = "SetBaseURI"
+)
+
+// NFT represents a ZRC-6 or legacy ZRC-1 non-fungible token contract.
+type NFT struct {
+	Address  string
+	Standard NFTStandard
+	zil      *Zillean
+}
+
+// NFTMintRequest describes a token to mint in NFT.BatchMint.
+type NFTMintRequest struct {
+	To       string
+	TokenURI string
+}
+
+// NFTTransferRequest describes a token to transfer in NFT.BatchTransferFrom.
+type NFTTransferRequest struct {
+	To      string
+	TokenID *big.Int
+}
+
+// NFTEvent describes a standard event of a non-fungible token contract. Batch events are split into an
+// event per token. Name is the event name as emitted by the contract, and the fields which do not
+// apply to the kind are left empty. URI is the token URI of Mint or the base URI of SetBaseURI.
+type NFTEvent struct {
+	Kind     NFTEventKind
+	Name     string
+	From     string
+	To       string
+	Spender  string
+	Operator string
+	TokenID  *big.Int
+	URI      string
+}
+
+// nftEventParams holds the params of the standard ZRC-6 and ZRC-1 events.
+type nftEventParams struct {
+	To           string     `scilla:"to"`
+	From         string     `scilla:"from"`
+	Recipient    string     `scilla:"recipient"`
+	TokenOwner   string     `scilla:"token_owner"`
+	Spender      string     `scilla:"spender"`
+	Operator     string     `scilla:"operator"`
+	Initiator    string     `scilla:"initiator"`
+	ApprovedAddr string     `scilla:"approved_addr"`
+	BurnAddress  string     `scilla:"burn_address"`
+	TokenID      *big.Int   `scilla:"token_id"`
+	Token        *big.Int   `scilla:"token"`
+	TokenURI     string     `scilla:"token_uri"`
+	BaseURI      string     `scilla:"base_uri"`
+	TokenIDList  []*big.Int `scilla:"token_id_list"`
+	MintList     []struct {
+		To       string
+		TokenURI string
+	} `scilla:"to_token_uri_pair_list"`
+	TransferList []struct {
+		To      string
+		TokenID *big.Int
+	} `scilla:"to_token_id_pair_list"`
+}
+
+// NewNFT returns a new NFT bound to a token contract address of a standard.
+func NewNFT(address string, standard NFTStandard, zil *Zillean) *NFT {
+	return &NFT{Address: strings.ToLower(strings.TrimPrefix(address, "0x")), Standard: standard, zil: zil}
+}
+
+// OwnerOf returns the owner of a token.
+func (n *NFT) OwnerOf(tokenID *big.Int) (string, error) {
+	var owner string
+	ok, err := n.zil.RPC.GetMapEntry(n.Address, "token_owners", []Value{Uint256(tokenID)}, "ByStr20", &owner)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("token %s does not exist", tokenID)
+	}
+	return owner, nil
+}
+
+// BalanceOf returns the number of tokens owned by an address.
+func (n *NFT) BalanceOf(owner string) (*big.Int, error) {
+	variable, valType := "balances", "Uint128"
+	if n.Standard == ZRC1 {
+		variable, valType = "owned_token_count", "Uint256"
+	}
+	var balance *big.Int
+	ok, err := n.zil.RPC.GetMapEntry(n.Address, variable, []Value{ByStr20(owner)}, valType, &balance)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return new(big.Int), nil
+	}
+	return balance, nil
+}
+
+// TotalSupply returns the number of existing tokens.
+func (n *NFT) TotalSupply() (*big.Int, error) {
+	valType := "Uint128"
+	if n.Standard == ZRC1 {
+		valType = "Uint256"
+	}
+	var supply *big.Int
+	if err := n.zil.RPC.getStateVariable(n.Address, "total_supply", valType, &supply); err != nil {
+		return nil, err
+	}
+	return supply, nil
+}
+
+// BaseURI returns the base URI of the tokens. It is supported by ZRC-6 only.
+func (n *NFT) BaseURI() (string, error) {
+	if err := n.require(ZRC6, "BaseURI"); err != nil {
+		return "", err
+	}
+	var baseURI string
+	if err := n.zil.RPC.getStateVariable(n.Address, "base_uri", "String", &baseURI); err != nil {
+		return "", err
+	}
+	return baseURI, nil
+}
+
+// TokenURI returns the URI of a token. For ZRC-6, a token without its own URI has the base URI followed by its ID.
+func (n *NFT) TokenURI(tokenID *big.Int) (string, error) {
+	if _, err := n.OwnerOf(tokenID); err != nil {
+		return "", err
+	}
+	var uri string
+	if _, err := n.zil.RPC.GetMapEntry(n.Address, "token_uris", []Value{Uint256(tokenID)}, "String", &uri); err != nil {
+		return "", err
+	}
+	if uri != "" || n.Standard == ZRC1 {
+		return uri, nil
+	}
+	baseURI, err := n.BaseURI()
+	if err != nil {
+		return "", err
+	}
+	return baseURI + tokenID.String(), nil
+}
+
+// SpenderOf returns the address approved to transfer a token, or an empty string if there is none.
+func (n *NFT) SpenderOf(tokenID *big.Int) (string, error) {
+	variable := "spenders"
+	if n.Standard == ZRC1 {
+		variable = "token_approvals"
+	}
+	var spender string
+	if _, err := n.zil.RPC.GetMapEntry(n.Address, variable, []Value{Uint256(tokenID)}, "ByStr20", &spender); err != nil {
+		return "", err
+	}
+	return spender, nil
+}
+
+// IsOperator checks whether an operator is approved to transfer all the tokens of an owner.
+func (n *NFT) IsOperator(owner, operator string) (bool, error) {
+	variable := "operators"
+	if n.Standard == ZRC1 {
+		variable = "operator_approvals"
+	}
+	var approved bool
+	if _, err := n.zil.RPC.GetMapEntry(n.Address, variable, []Value{ByStr20(owner), ByStr20(operator)}, "Bool", &approved); err != nil {
+		return false, err
+	}
+	return approved, nil
+}
+
+// Mint mints a token with a URI to an address. ZRC-6 contracts assign the token ID, so tokenID must be nil,
+// while ZRC-1 contracts require it.
+func (n *NFT) Mint(ctx context.Context, privateKey, to string, tokenID *big.Int, tokenURI string, opts *TxOptions) (*ContractResult, error) {
+	if n.Standard == ZRC1 {
+		return n.call(ctx, privateKey, "Mint", opts,
+			Param{"to", ByStr20(to)},
+			Param{"token_id", Uint256(tokenID)},
+			Param{"token_uri", String(tokenURI)},
+		)
+	}
+	if tokenID != nil {
+		return nil, fmt.Errorf("%s contracts assign token IDs", n.Standard)
+	}
+	return n.call(ctx, privateKey, "Mint", opts,
+		Param{"to", ByStr20(to)},
+		Param{"token_uri", String(tokenURI)},
+	)
+}
+
+// BatchMint mints many tokens at once. It is supported by ZRC-6 only.
+func (n *NFT) BatchMint(ctx context.Context, privateKey string, mints []NFTMintRequest, opts *TxOptions) (*ContractResult, error) {
+	if err := n.require(ZRC6, "BatchMint"); err != nil {
+		return nil, err
+	}
+	pairs := make([]Value, len(mints))
+	for i, mint := range mints {
+		pairs[i] = Pair(ByStr20(mint.To), String(mint.TokenURI))
+	}
+	return n.call(ctx, privateKey, "BatchMint", opts,
+		Param{"to_token_uri_pair_list", List(applyType("Pair", "ByStr20", "String"), pairs...)},
+	)
+}
+
+// Burn burns a token.
+func (n *NFT) Burn(ctx context.Context, privateKey string, tokenID *big.Int, opts *TxOptions) (*ContractResult, error) {
+	return n.call(ctx, privateKey, "Burn", opts, Param{"token_id", Uint256(tokenID)})
+}
+
+// BatchBurn burns many tokens at once. It is supported by ZRC-6 only.
+func (n *NFT) BatchBurn(ctx context.Context, privateKey string, tokenIDs []*big.Int, opts *TxOptions) (*ContractResult, error) {
+	if err := n.require(ZRC6, "BatchBurn"); err != nil {
+		return nil, err
+	}
+	ids := make([]Value, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		ids[i] = Uint256(tokenID)
+	}
+	return n.call(ctx, privateKey, "BatchBurn", opts, Param{"token_id_list", List("Uint256", ids...)})
+}
+
+// TransferFrom transfers a token to an address, as its owner, spender or operator.
+func (n *NFT) TransferFrom(ctx context.Context, privateKey, to string, tokenID *big.Int, opts *TxOptions) (*ContractResult, error) {
+	return n.call(ctx, privateKey, "TransferFrom", opts,
+		Param{"to", ByStr20(to)},
+		Param{"token_id", Uint256(tokenID)},
+	)
+}
+
+// BatchTransferFrom transfers many tokens at once. It is supported by ZRC-6 only.
+func (n *NFT) BatchTransferFrom(ctx context.Context, privateKey string, transfers []NFTTransferRequest, opts *TxOptions) (*ContractResult, error) {
+	if err := n.require(ZRC6, "BatchTransferFrom"); err != nil {
+		return nil, err
+	}
+	pairs := make([]Value, len(transfers))
+	for i, transfer := range transfers {
+		pairs[i] = Pair(ByStr20(transfer.To), Uint256(transfer.TokenID))
+	}
+	return n.call(ctx, privateKey, "BatchTransferFrom", opts,
+		Param{"to_token_id_pair_list", List(applyType("Pair", "ByStr20", "Uint256"), pairs...)},
+	)
+}
+
+// SetSpender approves an address to transfer a token. ZRC-1 contracts call it SetApprove.
+func (n *NFT) SetSpender(ctx context.Context, privateKey, spender string, tokenID *big.Int, opts *TxOptions) (*ContractResult, error) {
+	if n.Standard == ZRC1 {
+		return n.call(ctx, privateKey, "SetApprove", opts,
+			Param{"to", ByStr20(spender)},
+			Param{"token_id", Uint256(tokenID)},
+		)
+	}
+	return n.call(ctx, privateKey, "SetSpender", opts,
+		Param{"spender", ByStr20(spender)},
+		Param{"token_id", Uint256(tokenID)},
+	)
+}
+
+// AddOperator approves an address to transfer all the tokens of the account of a private key.
+// It is supported by ZRC-6 only, since the SetApprovalForAll transition of ZRC-1 toggles the approval.
+func (n *NFT) AddOperator(ctx context.Context, privateKey, operator string, opts *TxOptions) (*ContractResult, error) {
+	if err := n.require(ZRC6, "AddOperator"); err != nil {
+		return nil, err
+	}
+	return n.call(ctx, privateKey, "AddOperator", opts, Param{"operator", ByStr20(operator)})
+}
+
+// RemoveOperator revokes the approval of an operator. It is supported by ZRC-6 only.
+func (n *NFT) RemoveOperator(ctx context.Context, privateKey, operator string, opts *TxOptions) (*ContractResult, error) {
+	if err := n.require(ZRC6, "RemoveOperator"); err != nil {
+		return nil, err
+	}
+	return n.call(ctx, privateKey, "RemoveOperator", opts, Param{"operator", ByStr20(operator)})
+}
+
+// Events returns the standard events of the token contract in a receipt.
+func (n *NFT) Events(receipt TransactionReceipt) ([]NFTEvent, error) {
+	var events []NFTEvent
+	for _, log := range receipt.EventLogs {
+		if strings.ToLower(strings.TrimPrefix(log.Address, "0x")) != n.Address {
+			continue
+		}
+		var p nftEventParams
+		if err := log.Unmarshal(&p); err != nil {
+			return nil, fmt.Errorf("%s: %v", log.EventName, err)
+		}
+		tokenID := p.TokenID
+		if tokenID == nil {
+			tokenID = p.Token
+		}
+
+		event := NFTEvent{Name: log.EventName}
+		switch log.EventName {
+		case "Mint":
+			event.Kind, event.To, event.TokenID, event.URI = NFTMint, p.To, tokenID, p.TokenURI
+		case "MintSuccess":
+			event.Kind, event.To, event.TokenID, event.URI = NFTMint, p.Recipient, tokenID, p.TokenURI
+		case "BatchMint":
+			for _, mint := range p.MintList {
+				events = append(events, NFTEvent{Kind: NFTMint, Name: log.EventName, To: mint.To, URI: mint.TokenURI})
+			}
+			continue
+		case "Burn":
+			event.Kind, event.From, event.TokenID = NFTBurn, p.TokenOwner, tokenID
+		case "BurnSuccess":
+			event.Kind, event.From, event.TokenID = NFTBurn, p.BurnAddress, tokenID
+		case "BatchBurn":
+			for _, id := range p.TokenIDList {
+				events = append(events, NFTEvent{Kind: NFTBurn, Name: log.EventName, TokenID: id})
+			}
+			continue
+		case "TransferFrom":
+			event.Kind, event.From, event.To, event.TokenID = NFTTransfer, p.From, p.To, tokenID
+		case "TransferSuccess", "TransferFromSuccess":
+			event.Kind, event.From, event.To, event.TokenID = NFTTransfer, p.From, p.Recipient, tokenID
+		case "BatchTransferFrom":
+			for _, transfer := range p.TransferList {
+				events = append(events, NFTEvent{Kind: NFTTransfer, Name: log.EventName, To: transfer.To, TokenID: transfer.TokenID})
+			}
+			continue
+		case "SetSpender":
+			event.Kind, event.From, event.Spender, event.TokenID = NFTSetSpender, p.TokenOwner, p.Spender, tokenID
+		case "SetApproveSuccess":
+			event.Kind, event.From, event.Spender, event.TokenID = NFTSetSpender, p.Initiator, p.ApprovedAddr, tokenID
+		case "AddOperator":
+			event.Kind, event.From, event.Operator = NFTAddOperator, p.TokenOwner, p.Operator
+		case "RemoveOperator":
+			event.Kind, event.From, event.Operator = NFTRemoveOperator, p.TokenOwner, p.Operator
+		case "SetBaseURI":
+			event.Kind, event.URI = NFTSetBaseURI, p.BaseURI
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (n *NFT) call(ctx context.Context, privateKey, transition string, opts *TxOptions, params ...Param) (*ContractResult, error) {
+	return n.zil.CallContract(ctx, privateKey, n.Address, transition, params, "0", opts)
+}
+
+func (n *NFT) require(standard NFTStandard, operation string) error {
+	if n.Standard != standard {
+		return fmt.Errorf("%s is not supported by %s", operation, n.Standard)
+	}
+	return nil
+}
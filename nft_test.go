@@ -0,0 +1,223 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newNFTNode returns a node stand-in of a ZRC-6 token contract holding tokens 1 and 2, where only token 1
+// has its own URI. It also accepts every transaction like newWalletNode.
+func newNFTNode(sent func(rawTx RawTransaction)) func(method string, params json.RawMessage) (interface{}, string) {
+	wallet := newWalletNode(0, sent)
+	state := map[string]interface{}{
+		"token_owners": map[string]string{"1": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b", "2": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},
+		"balances":     map[string]string{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b": "1", "0x4baf5fada8e5db92c3d3242618c5b47133ae003c": "1"},
+		"token_uris":   map[string]string{"1": "ipfs://token-1"},
+		"spenders":     map[string]string{"2": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},
+		"operators": map[string]interface{}{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b": map[string]interface{}{
+			"0x4baf5fada8e5db92c3d3242618c5b47133ae003c": map[string]interface{}{"constructor": "True", "argtypes": []string{}, "arguments": []string{}},
+		}},
+	}
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		if method != "GetSmartContractSubState" {
+			return wallet(method, params)
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		var variable string
+		var indices []string
+		json.Unmarshal(args[1], &variable)
+		json.Unmarshal(args[2], &indices)
+		switch variable {
+		case "total_supply":
+			return map[string]string{"total_supply": "2"}, ""
+		case "base_uri":
+			return map[string]string{"base_uri": "https://example.com/tokens/"}, ""
+		}
+
+		value, ok := state[variable]
+		for _, index := range indices {
+			if !ok {
+				break
+			}
+			switch m := value.(type) {
+			case map[string]string:
+				value, ok = m[index]
+			case map[string]interface{}:
+				value, ok = m[index]
+			}
+		}
+		if !ok {
+			return nil, ""
+		}
+		// Wrap the entry in the maps of its indices, as the node returns it.
+		for i := len(indices) - 1; i >= 0; i-- {
+			value = map[string]interface{}{indices[i]: value}
+		}
+		return map[string]interface{}{variable: value}, ""
+	}
+}
+
+func TestNFT_State(t *testing.T) {
+	Convey("returns the owners, URIs, balances and approvals of the tokens", t, func() {
+		node := newStubNode(newNFTNode(nil))
+		defer node.Close()
+		nft := NewNFT(tokenAddress, ZRC6, NewZillean(node.URL))
+
+		owner, err := nft.OwnerOf(big.NewInt(2))
+		So(err, ShouldBeNil)
+		So(owner, ShouldEqual, "0x4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		_, err = nft.OwnerOf(big.NewInt(3))
+		So(err.Error(), ShouldEqual, "token 3 does not exist")
+
+		uri, err := nft.TokenURI(big.NewInt(1))
+		So(err, ShouldBeNil)
+		So(uri, ShouldEqual, "ipfs://token-1")
+		uri, err = nft.TokenURI(big.NewInt(2))
+		So(err, ShouldBeNil)
+		So(uri, ShouldEqual, "https://example.com/tokens/2")
+
+		balance, err := nft.BalanceOf("0xF49F1306BC8FB0CD8167A58A3550C1443072E96B")
+		So(err, ShouldBeNil)
+		So(balance.Int64(), ShouldEqual, 1)
+		balance, err = nft.BalanceOf("0000000000000000000000000000000000000000")
+		So(err, ShouldBeNil)
+		So(balance.Sign(), ShouldEqual, 0)
+
+		supply, err := nft.TotalSupply()
+		So(err, ShouldBeNil)
+		So(supply.Int64(), ShouldEqual, 2)
+
+		spender, err := nft.SpenderOf(big.NewInt(2))
+		So(err, ShouldBeNil)
+		So(spender, ShouldEqual, "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b")
+		spender, err = nft.SpenderOf(big.NewInt(1))
+		So(err, ShouldBeNil)
+		So(spender, ShouldBeBlank)
+
+		approved, err := nft.IsOperator("f49f1306bc8fb0cd8167a58a3550c1443072e96b", "4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		So(err, ShouldBeNil)
+		So(approved, ShouldBeTrue)
+		approved, err = nft.IsOperator("4baf5fada8e5db92c3d3242618c5b47133ae003c", "f49f1306bc8fb0cd8167a58a3550c1443072e96b")
+		So(err, ShouldBeNil)
+		So(approved, ShouldBeFalse)
+	})
+}
+
+func TestNFT_Calls(t *testing.T) {
+	Convey("calls the ZRC-6 transitions", t, func() {
+		var rawTx RawTransaction
+		node := newStubNode(newNFTNode(func(tx RawTransaction) { rawTx = tx }))
+		defer node.Close()
+		nft := NewNFT(tokenAddress, ZRC6, NewZillean(node.URL))
+		ctx, opts := context.Background(), &TxOptions{Wait: fastWait}
+
+		_, err := nft.Mint(ctx, testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", nil, "ipfs://token-3", opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"Mint","params":[{"vname":"to","type":"ByStr20","value":"0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},`+
+			`{"vname":"token_uri","type":"String","value":"ipfs://token-3"}]}`)
+
+		_, err = nft.BatchMint(ctx, testVectors[0].privateKey, []NFTMintRequest{{"4baf5fada8e5db92c3d3242618c5b47133ae003c", ""}}, opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"BatchMint","params":[{"vname":"to_token_uri_pair_list","type":"List (Pair (ByStr20) (String))","value":`+
+			`[{"constructor":"Pair","argtypes":["ByStr20","String"],"arguments":["0x4baf5fada8e5db92c3d3242618c5b47133ae003c",""]}]}]}`)
+
+		_, err = nft.BatchTransferFrom(ctx, testVectors[0].privateKey, []NFTTransferRequest{{"4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(1)}}, opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"BatchTransferFrom","params":[{"vname":"to_token_id_pair_list","type":"List (Pair (ByStr20) (Uint256))","value":`+
+			`[{"constructor":"Pair","argtypes":["ByStr20","Uint256"],"arguments":["0x4baf5fada8e5db92c3d3242618c5b47133ae003c","1"]}]}]}`)
+
+		_, err = nft.BatchBurn(ctx, testVectors[0].privateKey, []*big.Int{big.NewInt(1), big.NewInt(2)}, opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"BatchBurn","params":[{"vname":"token_id_list","type":"List (Uint256)","value":["1","2"]}]}`)
+
+		_, err = nft.SetSpender(ctx, testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(1), opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldContainSubstring, `"_tag":"SetSpender"`)
+
+		_, err = nft.AddOperator(ctx, testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"AddOperator","params":[{"vname":"operator","type":"ByStr20","value":"0x4baf5fada8e5db92c3d3242618c5b47133ae003c"}]}`)
+
+		_, err = nft.Mint(ctx, testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(3), "", opts)
+		So(err.Error(), ShouldEqual, "ZRC-6 contracts assign token IDs")
+	})
+
+	Convey("calls the ZRC-1 transitions", t, func() {
+		var rawTx RawTransaction
+		node := newStubNode(newNFTNode(func(tx RawTransaction) { rawTx = tx }))
+		defer node.Close()
+		nft := NewNFT(tokenAddress, ZRC1, NewZillean(node.URL))
+		ctx, opts := context.Background(), &TxOptions{Wait: fastWait}
+
+		_, err := nft.Mint(ctx, testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(3), "ipfs://token-3", opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"Mint","params":[{"vname":"to","type":"ByStr20","value":"0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},`+
+			`{"vname":"token_id","type":"Uint256","value":"3"},{"vname":"token_uri","type":"String","value":"ipfs://token-3"}]}`)
+
+		_, err = nft.SetSpender(ctx, testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(3), opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"SetApprove","params":[{"vname":"to","type":"ByStr20","value":"0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},`+
+			`{"vname":"token_id","type":"Uint256","value":"3"}]}`)
+
+		_, err = nft.TransferFrom(ctx, testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(3), opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldContainSubstring, `"_tag":"TransferFrom"`)
+
+		_, err = nft.AddOperator(ctx, testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", opts)
+		So(err.Error(), ShouldEqual, "AddOperator is not supported by ZRC-1")
+		_, err = nft.BaseURI()
+		So(err.Error(), ShouldEqual, "BaseURI is not supported by ZRC-1")
+	})
+}
+
+func TestNFT_Events(t *testing.T) {
+	Convey("returns the standard events of the token in a receipt", t, func() {
+		var receipt TransactionReceipt
+		So(json.Unmarshal([]byte(`{"event_logs": [
+			{"_eventname": "Mint", "address": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "params": [
+				{"vname": "to", "type": "ByStr20", "value": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},
+				{"vname": "token_id", "type": "Uint256", "value": "3"},
+				{"vname": "token_uri", "type": "String", "value": "ipfs://token-3"}]},
+			{"_eventname": "BatchTransferFrom", "address": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "params": [
+				{"vname": "to_token_id_pair_list", "type": "List (Pair ByStr20 Uint256)", "value": [
+					{"constructor": "Pair", "argtypes": ["ByStr20", "Uint256"], "arguments": ["0xf49f1306bc8fb0cd8167a58a3550c1443072e96b", "1"]},
+					{"constructor": "Pair", "argtypes": ["ByStr20", "Uint256"], "arguments": ["0xf49f1306bc8fb0cd8167a58a3550c1443072e96b", "2"]}]}]},
+			{"_eventname": "TransferFromSuccess", "address": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "params": [
+				{"vname": "from", "type": "ByStr20", "value": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},
+				{"vname": "recipient", "type": "ByStr20", "value": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},
+				{"vname": "token", "type": "Uint256", "value": "7"}]},
+			{"_eventname": "AddOperator", "address": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "params": [
+				{"vname": "token_owner", "type": "ByStr20", "value": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},
+				{"vname": "operator", "type": "ByStr20", "value": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c"}]},
+			{"_eventname": "Mint", "address": "0x0000000000000000000000000000000000000001", "params": []},
+			{"_eventname": "Custom", "address": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "params": []}
+		], "success": true}`), &receipt), ShouldBeNil)
+
+		events, err := NewNFT(tokenAddress, ZRC6, nil).Events(receipt)
+		So(err, ShouldBeNil)
+		So(len(events), ShouldEqual, 5)
+
+		So(events[0].Kind, ShouldEqual, NFTMint)
+		So(events[0].To, ShouldEqual, "0x4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		So(events[0].TokenID.Int64(), ShouldEqual, 3)
+		So(events[0].URI, ShouldEqual, "ipfs://token-3")
+
+		So(events[1].Kind, ShouldEqual, NFTTransfer)
+		So(events[1].Name, ShouldEqual, "BatchTransferFrom")
+		So(events[1].TokenID.Int64(), ShouldEqual, 1)
+		So(events[2].TokenID.Int64(), ShouldEqual, 2)
+
+		So(events[3].Kind, ShouldEqual, NFTTransfer)
+		So(events[3].From, ShouldEqual, "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b")
+		So(events[3].To, ShouldEqual, "0x4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		So(events[3].TokenID.Int64(), ShouldEqual, 7)
+
+		So(events[4].Kind, ShouldEqual, NFTAddOperator)
+		So(events[4].Operator, ShouldEqual, "0x4baf5fada8e5db92c3d3242618c5b47133ae003c")
+	})
+}
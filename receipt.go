@@ -0,0 +1,121 @@
+package zillean
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ReceiptError represents an error code in a transaction receipt.
+type ReceiptError int
+
+// Receipt error codes reported by Zilliqa nodes.
+const (
+	CheckerFailed ReceiptError = iota
+	RunnerFailed
+	BalanceTransferFailed
+	ExecuteCmdFailed
+	ExecuteCmdTimeout
+	NoGasRemainingFound
+	NoAcceptedFound
+	CallContractFailed
+	CreateContractFailed
+	JSONOutputCorrupted
+	ContractNotExist
+	StateCorrupted
+	LogEntryInstallFailed
+	MessageCorrupted
+	ReceiptIsNull
+	MaxEdgesReached
+	ChainCallDiffShard
+	PreparationFailed
+	NoOutput
+	OutputIllegal
+	MapDepthMissing
+	GasNotSufficient
+	InternalError
+	LibraryAsRecipient
+	VersionInconsistent
+	LibraryExtractionFailed
+)
+
+var receiptErrorNames = []string{
+	"CHECKER_FAILED",
+	"RUNNER_FAILED",
+	"BALANCE_TRANSFER_FAILED",
+	"EXECUTE_CMD_FAILED",
+	"EXECUTE_CMD_TIMEOUT",
+	"NO_GAS_REMAINING_FOUND",
+	"NO_ACCEPTED_FOUND",
+	"CALL_CONTRACT_FAILED",
+	"CREATE_CONTRACT_FAILED",
+	"JSON_OUTPUT_CORRUPTED",
+	"CONTRACT_NOT_EXIST",
+	"STATE_CORRUPTED",
+	"LOG_ENTRY_INSTALL_FAILED",
+	"MESSAGE_CORRUPTED",
+	"RECEIPT_IS_NULL",
+	"MAX_EDGES_REACHED",
+	"CHAIN_CALL_DIFF_SHARD",
+	"PREPARATION_FAILED",
+	"NO_OUTPUT",
+	"OUTPUT_ILLEGAL",
+	"MAP_DEPTH_MISSING",
+	"GAS_NOT_SUFFICIENT",
+	"INTERNAL_ERROR",
+	"LIBRARY_AS_RECIPIENT",
+	"VERSION_INCONSISTENT",
+	"LIBRARY_EXTRACTION_FAILED",
+}
+
+func (e ReceiptError) String() string {
+	if e >= 0 && int(e) < len(receiptErrorNames) {
+		return receiptErrorNames[e]
+	}
+	return fmt.Sprintf("UNKNOWN_ERROR(%d)", int(e))
+}
+
+// EventLogsByName returns the event logs with a given event name.
+func (r *TransactionReceipt) EventLogsByName(eventName string) []EventLog {
+	var logs []EventLog
+	for _, log := range r.EventLogs {
+		if log.EventName == eventName {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+// ErrorsAt returns the error codes raised at a given call depth.
+func (r *TransactionReceipt) ErrorsAt(depth int) []ReceiptError {
+	return r.Errors[depth]
+}
+
+// Param returns the parameter with a given name.
+func (l EventLog) Param(vname string) (ContractParam, bool) {
+	return findParam(l.Params, vname)
+}
+
+// Param returns the parameter with a given name.
+func (m TransitionMessage) Param(vname string) (ContractParam, bool) {
+	return findParam(m.Params, vname)
+}
+
+func findParam(params []ContractParam, vname string) (ContractParam, bool) {
+	for _, param := range params {
+		if param.Vname == vname {
+			return param, true
+		}
+	}
+	return ContractParam{}, false
+}
+
+// String returns the value as a string. String values such as Uint128 or ByStr20 are unquoted,
+// while lists and ADTs are returned as raw JSON.
+func (p ContractParam) String() string {
+	var s string
+	if err := json.Unmarshal(p.Value, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(p.Value))
+}
@@ -0,0 +1,307 @@
+package zillean
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RecorderMode specifies how a Recorder handles JSON-RPC requests.
+type RecorderMode int
+
+const (
+	// ReplayMode serves responses from previously recorded fixtures without touching the network.
+	ReplayMode RecorderMode = iota
+	// RecordMode forwards requests to the node and stores the responses as fixtures.
+	RecordMode
+)
+
+// Recorder is an http.RoundTripper which records JSON-RPC request/response pairs into golden files
+// and replays them by method and params.
+type Recorder struct {
+	Dir       string
+	Mode      RecorderMode
+	Transport http.RoundTripper
+}
+
+// NewRecorder returns a new Recorder which keeps its fixtures in a given directory.
+func NewRecorder(dir string, mode RecorderMode) *Recorder {
+	return &Recorder{
+		Dir:       dir,
+		Mode:      mode,
+		Transport: http.DefaultTransport,
+	}
+}
+
+// Fixture describes a recorded JSON-RPC request/response pair.
+type Fixture struct {
+	Method   string          `json:"method"`
+	Params   json.RawMessage `json:"params"`
+	Response json.RawMessage `json:"response"`
+}
+
+// SchemaDriftError is returned in RecordMode when a newly recorded response differs in shape from the existing fixture.
+// The new fixture is written anyway, so re-running the tests after reviewing the drift succeeds.
+type SchemaDriftError struct {
+	Method string
+	Diffs  []string
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("schema drift in %s: %s", e.Method, strings.Join(e.Diffs, ", "))
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+
+	var rpcReq rpcRequest
+	if err := json.Unmarshal(body, &rpcReq); err != nil {
+		return nil, err
+	}
+
+	path, err := rec.fixturePath(rpcReq.Method, rpcReq.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	if rec.Mode == RecordMode {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return rec.record(req, rpcReq, path)
+	}
+
+	return rec.replay(req, rpcReq, path)
+}
+
+func (rec *Recorder) record(req *http.Request, rpcReq rpcRequest, path string) (*http.Response, error) {
+	resp, err := rec.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	if prev, err := readFixture(path); err == nil {
+		diffs = diffShapes(prev.Response, respBody)
+	}
+
+	params := rpcReq.Params
+	if len(params) == 0 {
+		params = json.RawMessage("[]")
+	}
+	data, err := json.MarshalIndent(Fixture{
+		Method:   rpcReq.Method,
+		Params:   params,
+		Response: respBody,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(rec.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+
+	if len(diffs) > 0 {
+		return nil, &SchemaDriftError{Method: rpcReq.Method, Diffs: diffs}
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+func (rec *Recorder) replay(req *http.Request, rpcReq rpcRequest, path string) (*http.Response, error) {
+	fixture, err := readFixture(path)
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for %s %s: %v", rpcReq.Method, rpcReq.Params, err)
+	}
+
+	// The request ID changes between runs, so the recorded one is replaced.
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(fixture.Response, &response); err != nil {
+		return nil, err
+	}
+	if rpcReq.ID != nil {
+		response["id"] = rpcReq.ID
+	}
+	respBody, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// fixturePath returns the golden file path for a method and its params.
+// Signatures are excluded from the key since EC-Schnorr signatures are randomized on every signing.
+func (rec *Recorder) fixturePath(method string, params json.RawMessage) (string, error) {
+	// A missing params member is treated the same as an empty list.
+	var p interface{} = []interface{}{}
+	if len(params) > 0 && string(params) != "null" {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return "", err
+		}
+	}
+	canonical, err := json.Marshal(stripSignature(p))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+
+	return filepath.Join(rec.Dir, fmt.Sprintf("%s-%x.json", method, sum[:6])), nil
+}
+
+func stripSignature(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if key == "signature" {
+				continue
+			}
+			m[key] = stripSignature(value)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, value := range v {
+			s[i] = stripSignature(value)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func readFixture(path string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, err
+	}
+	return &fixture, nil
+}
+
+// diffShapes compares the JSON structure (keys and value kinds) of two documents and returns the differences.
+func diffShapes(prev, next []byte) []string {
+	var p, n interface{}
+	if err := json.Unmarshal(prev, &p); err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(next, &n); err != nil {
+		return []string{"response is not valid JSON"}
+	}
+
+	prevShape, nextShape := map[string]string{}, map[string]string{}
+	jsonShape(p, "$", prevShape)
+	jsonShape(n, "$", nextShape)
+
+	var diffs []string
+	for path, kind := range prevShape {
+		if underEmptyArray(path, prevShape) || underEmptyArray(path, nextShape) {
+			continue
+		}
+		nextKind, ok := nextShape[path]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s removed", path))
+		case kind != nextKind:
+			diffs = append(diffs, fmt.Sprintf("%s changed from %s to %s", path, kind, nextKind))
+		}
+	}
+	for path := range nextShape {
+		if underEmptyArray(path, prevShape) || underEmptyArray(path, nextShape) {
+			continue
+		}
+		if _, ok := prevShape[path]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s added", path))
+		}
+	}
+	sort.Strings(diffs)
+
+	return diffs
+}
+
+func jsonShape(v interface{}, path string, shape map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		shape[path] = "object"
+		for key, value := range v {
+			// The request ID is not part of the response schema.
+			if path == "$" && key == "id" {
+				continue
+			}
+			jsonShape(value, path+"."+key, shape)
+		}
+	case []interface{}:
+		shape[path] = "array"
+		if len(v) == 0 {
+			shape[path+"[]"] = "empty"
+		}
+		for _, value := range v {
+			jsonShape(value, path+"[]", shape)
+		}
+	case string:
+		shape[path] = "string"
+	case float64:
+		shape[path] = "number"
+	case bool:
+		shape[path] = "bool"
+	case nil:
+		// null values carry no type information, so they are not compared.
+	}
+}
+
+// underEmptyArray reports whether a path lies inside an array which is empty in a given shape,
+// in which case the element structure cannot be compared.
+func underEmptyArray(path string, shape map[string]string) bool {
+	for i := strings.Index(path, "[]"); i >= 0; {
+		if shape[path[:i+2]] == "empty" {
+			return true
+		}
+		next := strings.Index(path[i+2:], "[]")
+		if next < 0 {
+			break
+		}
+		i += next + 2
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package zillean
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newBalanceNode(balance map[string]interface{}) *httptest.Server {
+	return newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+		return balance, ""
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	Convey("records the response of a node and replays it without the node", t, func() {
+		dir, _ := ioutil.TempDir("", "zillean")
+		defer os.RemoveAll(dir)
+		node := newBalanceNode(map[string]interface{}{"balance": "3000000000000", "nonce": 2})
+
+		result, err := NewRPCWithTransport(node.URL, NewRecorder(dir, RecordMode)).GetBalance("Df4B175C78e16EeBC05173E5C1f87355622D8104")
+		So(err, ShouldBeNil)
+		So(result.Balance, ShouldEqual, "3000000000000")
+		node.Close()
+
+		result, err = NewRPCWithTransport(node.URL, NewRecorder(dir, ReplayMode)).GetBalance("Df4B175C78e16EeBC05173E5C1f87355622D8104")
+		So(err, ShouldBeNil)
+		So(result.Balance, ShouldEqual, "3000000000000")
+		So(result.Nonce, ShouldEqual, 2)
+
+		_, err = NewRPCWithTransport(node.URL, NewRecorder(dir, ReplayMode)).GetBalance("4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("detects schema drift when a response is re-recorded", t, func() {
+		dir, _ := ioutil.TempDir("", "zillean")
+		defer os.RemoveAll(dir)
+		node := newBalanceNode(map[string]interface{}{"balance": "3000000000000", "nonce": 2})
+		_, err := NewRPCWithTransport(node.URL, NewRecorder(dir, RecordMode)).GetBalance("Df4B175C78e16EeBC05173E5C1f87355622D8104")
+		So(err, ShouldBeNil)
+		node.Close()
+
+		node = newBalanceNode(map[string]interface{}{"balance": 3000000000000, "nonce": 2, "frozen": false})
+		defer node.Close()
+		_, err = NewRPCWithTransport(node.URL, NewRecorder(dir, RecordMode)).GetBalance("Df4B175C78e16EeBC05173E5C1f87355622D8104")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "$.result.balance changed from string to number")
+		So(err.Error(), ShouldContainSubstring, "$.result.frozen added")
+	})
+}
+
+func TestDiffShapes(t *testing.T) {
+	Convey("ignores the elements of arrays which are empty on either side", t, func() {
+		prev := []byte(`{"result":{"powWinners":[]}}`)
+		next := []byte(`{"result":{"powWinners":["0x02"]}}`)
+		So(diffShapes(prev, next), ShouldBeEmpty)
+		So(diffShapes(next, prev), ShouldBeEmpty)
+	})
+}
@@ -1,7 +1,9 @@
 package zillean
 
 import (
+	"encoding/json"
 	"errors"
+	"net/http"
 
 	"github.com/GincoInc/jsonrpc"
 )
@@ -18,6 +20,16 @@ func NewRPC(endpoint string) *RPC {
 	}
 }
 
+// NewRPCWithTransport returns a new RPC object which sends requests through a given transport.
+// This can be used with a Recorder to record and replay node responses.
+func NewRPCWithTransport(endpoint string, transport http.RoundTripper) *RPC {
+	client := jsonrpc.NewRPCClient(endpoint)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	return &RPC{
+		client: client,
+	}
+}
+
 // GetNetworkID returns the network ID of the specified zilliqa node.
 func (r *RPC) GetNetworkID() (string, error) {
 	resp, err := r.client.Call("GetNetworkId", []interface{}{})
@@ -46,7 +58,9 @@ func (r *RPC) GetBlockchainInfo() (*BlockchainInfo, error) {
 	}
 
 	var result BlockchainInfo
-	resp.GetObject(&result)
+	if err := resp.GetObject(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -78,7 +92,9 @@ func (r *RPC) GetDsBlock(blockNumber string) (*DsBlock, error) {
 	}
 
 	var result DsBlock
-	resp.GetObject(&result)
+	if err := resp.GetObject(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -94,7 +110,9 @@ func (r *RPC) GetLatestDsBlock() (*DsBlock, error) {
 	}
 
 	var result DsBlock
-	resp.GetObject(&result)
+	if err := resp.GetObject(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -147,7 +165,9 @@ func (r *RPC) DSBlockListing(pageNumber int64) (*ListedBlocks, error) {
 	}
 
 	var result ListedBlocks
-	resp.GetObject(&result)
+	if err := resp.GetObject(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -163,7 +183,28 @@ func (r *RPC) GetTxBlock(blockNumber string) (*TxBlock, error) {
 	}
 
 	var result TxBlock
-	resp.GetObject(&result)
+	if err := resp.GetObject(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTxBlockVerbose returns details of a Transaction block by block number, with the co-signature bitmaps
+// and first-round co-signature needed by VerifyCoSignature.
+func (r *RPC) GetTxBlockVerbose(blockNumber string) (*TxBlock, error) {
+	resp, err := r.client.Call("GetTxBlockVerbose", []interface{}{blockNumber})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, errors.New(resp.Error.Message)
+	}
+
+	var result TxBlock
+	if err := resp.GetObject(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -179,7 +220,9 @@ func (r *RPC) GetLatestTxBlock() (*TxBlock, error) {
 	}
 
 	var result TxBlock
-	resp.GetObject(&result)
+	if err := resp.GetObject(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -230,7 +273,9 @@ func (r *RPC) TxBlockListing(pageNumber int64) (*ListedBlocks, error) {
 	}
 
 	var result ListedBlocks
-	resp.GetObject(&result)
+	if err := resp.GetObject(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -509,6 +554,27 @@ func (r *RPC) GetSmartContractState(contractAddress string) ([]SmartContractStat
 	return result, nil
 }
 
+// GetSmartContractSubState returns a state variable (mutable) of a smart contract address, keyed by its name.
+// For a map variable, indices select the entry at the nested keys given in their string form, e.g. an address
+// for a Map (ByStr20) (Uint128). The result is nil if the variable or the entry does not exist.
+func (r *RPC) GetSmartContractSubState(contractAddress, variableName string, indices []string) (map[string]json.RawMessage, error) {
+	if indices == nil {
+		indices = []string{}
+	}
+	resp, err := r.client.Call("GetSmartContractSubState", []interface{}{contractAddress, variableName, indices})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, errors.New(resp.Error.Message)
+	}
+
+	var result map[string]json.RawMessage
+	resp.GetObject(&result)
+	return result, nil
+}
+
 // GetSmartContracts returns the list of smart contracts created by an address.
 func (r *RPC) GetSmartContracts(address string) ([]SmartContract, error) {
 	resp, err := r.client.Call("GetSmartContracts", []interface{}{address})
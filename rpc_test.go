@@ -1,13 +1,65 @@
 package zillean
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// newTestRPC returns an RPC which replays the node responses recorded in testdata/fixtures.
+// Set ZILLEAN_RECORD=1 to re-record them against testNet.
+func newTestRPC() *RPC {
+	mode := ReplayMode
+	if os.Getenv("ZILLEAN_RECORD") != "" {
+		mode = RecordMode
+	}
+	return NewRPCWithTransport(testNet, NewRecorder(filepath.Join("testdata", "fixtures"), mode))
+}
+
+// newStubNode returns a JSON-RPC node stand-in which answers every request with handle.
+// A non-empty error message is returned as a JSON-RPC error.
+func newStubNode(handle func(method string, params json.RawMessage) (interface{}, string)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		result, errMsg := handle(req.Method, req.Params)
+		if errMsg != "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": req.ID, "jsonrpc": "2.0", "error": map[string]interface{}{"code": -20, "message": errMsg}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": req.ID, "jsonrpc": "2.0", "result": result})
+	}))
+}
+
+// testTxID is the ID of the transaction served by newReceiptNode.
+const testTxID = "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5"
+
+// newReceiptNode returns a node stand-in serving a contract call whose receipt is a given JSON object.
+func newReceiptNode(receipt string) func(method string, params json.RawMessage) (interface{}, string) {
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		if method != "GetTransaction" {
+			return nil, "METHOD_NOT_FOUND: The method being requested is not available on this server"
+		}
+		return map[string]interface{}{
+			"ID":      testTxID,
+			"amount":  "0",
+			"receipt": json.RawMessage(receipt),
+			"toAddr":  "6c1169e8a77d34d6d615862db5f62f0a9791cb9f",
+		}, ""
+	}
+}
+
 func TestNewRPC(t *testing.T) {
 	Convey("returns a new rpc", t, func() {
 		So(NewRPC(localNet), ShouldHaveSameTypeAs, &RPC{})
@@ -16,7 +68,7 @@ func TestNewRPC(t *testing.T) {
 
 func TestRPC_GetNetworkID(t *testing.T) {
 	Convey("returns the network ID of the specified zilliqa node", t, func() {
-		result, err := NewRPC(testNet).GetNetworkID()
+		result, err := newTestRPC().GetNetworkID()
 		So(err, ShouldBeNil)
 		So(result, ShouldEqual, "333")
 	})
@@ -24,18 +76,18 @@ func TestRPC_GetNetworkID(t *testing.T) {
 
 func TestRPC_GetBlockchainInfo(t *testing.T) {
 	Convey("returns statistics about the specified zilliqa node", t, func() {
-		result, err := NewRPC(testNet).GetBlockchainInfo()
+		result, err := newTestRPC().GetBlockchainInfo()
 		So(err, ShouldBeNil)
-		So(result.CurrentDSEpoch, ShouldNotBeBlank)
-		So(result.CurrentMiniEpoch, ShouldNotBeBlank)
+		So(result.CurrentDSEpoch, ShouldBeGreaterThan, 0)
+		So(result.CurrentMiniEpoch, ShouldBeGreaterThan, 0)
 		So(result.DSBlockRate, ShouldBeGreaterThan, 0)
-		So(result.NumDSBlocks, ShouldNotBeBlank)
+		So(result.NumDSBlocks, ShouldBeGreaterThan, 0)
 		So(result.NumPeers, ShouldBeGreaterThan, 0)
-		So(result.NumTransactions, ShouldNotBeBlank)
-		So(result.NumTxBlocks, ShouldNotBeBlank)
-		So(result.NumTxnsDSEpoch, ShouldNotBeBlank)
+		So(result.NumTransactions, ShouldBeGreaterThan, 0)
+		So(result.NumTxBlocks, ShouldBeGreaterThan, 0)
+		So(result.NumTxnsDSEpoch, ShouldHaveSameTypeAs, Number(0))
 		So(result.NumTxnsTxEpoch, ShouldHaveSameTypeAs, int64(0))
-		So(result.CurrentDSEpoch, ShouldNotBeBlank)
+		So(result.CurrentDSEpoch.String(), ShouldNotBeBlank)
 		So(result.ShardingStructure.NumPeers, ShouldHaveSameTypeAs, []int64{})
 		So(len(result.ShardingStructure.NumPeers), ShouldBeGreaterThan, 0)
 		So(result.TransactionRate, ShouldHaveSameTypeAs, int64(0))
@@ -45,7 +97,7 @@ func TestRPC_GetBlockchainInfo(t *testing.T) {
 
 func TestRPC_GetShardingStructure(t *testing.T) {
 	Convey("returns the current sharding structure of the network from the specified network's lookup node", t, func() {
-		result, err := NewRPC(testNet).GetShardingStructure()
+		result, err := newTestRPC().GetShardingStructure()
 		So(err, ShouldBeNil)
 		fmt.Println(result)
 		So(len(result.NumPeers), ShouldBeGreaterThan, 0)
@@ -55,38 +107,39 @@ func TestRPC_GetShardingStructure(t *testing.T) {
 
 func TestRPC_GetDsBlock(t *testing.T) {
 	Convey("returns details of a Directory Service block by block number", t, func() {
-		result, err := NewRPC(testNet).GetDsBlock("1")
+		result, err := newTestRPC().GetDsBlock("1")
 		So(err, ShouldBeNil)
-		So(result.Header.BlockNum, ShouldEqual, "1")
+		So(result.Header.BlockNum, ShouldEqual, Number(1))
 		So(result.Header.Difficulty, ShouldEqual, 3)
 		So(result.Header.DifficultyDS, ShouldEqual, 5)
-		So(result.Header.GasPrice, ShouldEqual, "1000000000")
+		So(result.Header.GasPrice.String(), ShouldEqual, "1000000000")
 		So(result.Header.LeaderPubKey, ShouldEqual, "0x02081DCD3D93A4406E6D90241931A4D8A28553EC7BA28AB5B51D35D992CA2C7383")
 		So(result.Header.PoWWinners, ShouldResemble, []string{"0x027409E2C105498DE346980A7BD917E93574D86CB3A13B3CE3C989B2E2A96D5A69"})
-		So(result.Header.Prevhash, ShouldEqual, "0f00e9d3175300fc287812d201edcfbfcb8165809606545595bf53700c524648")
-		So(result.Header.Timestamp, ShouldEqual, "1549265830654931")
+		So(result.Header.Prevhash.String(), ShouldEqual, "0f00e9d3175300fc287812d201edcfbfcb8165809606545595bf53700c524648")
+		So(result.Header.Timestamp.Micros(), ShouldEqual, uint64(1549265830654931))
+		So(result.Header.Timestamp.Year(), ShouldEqual, 2019)
 		So(result.Signature, ShouldEqual, "CF8A45F50153BC860582DAFEEE074CC3D027DB94D839102BD777EF8AB1F5753163F2223E405B88F3A27D878465B4B9087BDB0D551C1EF954010FC99E8EB265A1")
 	})
 }
 
 func TestRPC_GetLatestDsBlock(t *testing.T) {
 	Convey("returns details of the most recent Directory Service block", t, func() {
-		result, err := NewRPC(testNet).GetLatestDsBlock()
+		result, err := newTestRPC().GetLatestDsBlock()
 		So(err, ShouldBeNil)
-		So(result.Header.BlockNum, ShouldNotBeBlank)
+		So(result.Header.BlockNum, ShouldBeGreaterThan, 0)
 		So(result.Header.Difficulty, ShouldBeGreaterThan, 0)
 		So(result.Header.DifficultyDS, ShouldBeGreaterThan, 0)
-		So(result.Header.GasPrice, ShouldNotBeBlank)
+		So(result.Header.GasPrice.Int().Sign(), ShouldBeGreaterThan, 0)
 		So(result.Header.LeaderPubKey, ShouldNotBeBlank)
-		So(result.Header.Prevhash, ShouldNotBeBlank)
-		So(result.Header.Timestamp, ShouldNotBeBlank)
+		So(result.Header.Prevhash, ShouldHaveLength, 32)
+		So(result.Header.Timestamp.IsZero(), ShouldBeFalse)
 		So(result.Signature, ShouldNotBeBlank)
 	})
 }
 
 func TestRPC_GetNumDSBlocks(t *testing.T) {
 	Convey("returns the number of Directory Service blocks in the network so far. This is represented as a String", t, func() {
-		result, err := NewRPC(testNet).GetNumDSBlocks()
+		result, err := newTestRPC().GetNumDSBlocks()
 		So(err, ShouldBeNil)
 		So(result, ShouldNotBeBlank)
 	})
@@ -94,7 +147,7 @@ func TestRPC_GetNumDSBlocks(t *testing.T) {
 
 func TestRPC_GetDSBlockRate(t *testing.T) {
 	Convey("returns the current Directory Service blockrate per second", t, func() {
-		result, err := NewRPC(testNet).GetDSBlockRate()
+		result, err := newTestRPC().GetDSBlockRate()
 		So(err, ShouldBeNil)
 		So(result, ShouldBeGreaterThan, 0)
 	})
@@ -102,7 +155,7 @@ func TestRPC_GetDSBlockRate(t *testing.T) {
 
 func TestRPC_DSBlockListing(t *testing.T) {
 	Convey("returns a paginated list of Directory Service blocks", t, func() {
-		result, err := NewRPC(testNet).DSBlockListing(1)
+		result, err := newTestRPC().DSBlockListing(1)
 		So(err, ShouldBeNil)
 		So(len(result.Data), ShouldEqual, 10)
 		So(result.MaxPages, ShouldBeGreaterThan, 0)
@@ -111,53 +164,86 @@ func TestRPC_DSBlockListing(t *testing.T) {
 
 func TestRPC_GetTxBlock(t *testing.T) {
 	Convey("returns details of a Transaction block by block number.", t, func() {
-		result, err := NewRPC(testNet).GetTxBlock("100")
+		result, err := newTestRPC().GetTxBlock("100")
 		So(err, ShouldBeNil)
 		So(result.Body.HeaderSign, ShouldEqual, "07968762C6819E0D17B8761B31F68A26D4AA189547213B4D74E00A09A3B7EECCC4AF8D87C74DE9188A69F25A15D524781BDCD3CE0BE9C594E0D4DBFE00ABAC2A")
 		So(len(result.Body.MicroBlockInfos), ShouldEqual, 4)
-		So(result.Header.BlockNum, ShouldEqual, "100")
-		So(result.Header.DsBlockNum, ShouldEqual, "2")
-		So(result.Header.GasLimit, ShouldEqual, "200000")
-		So(result.Header.GasUsed, ShouldEqual, "0")
-		So(result.Header.MbInfoHash, ShouldEqual, "db311f58e5c43b043f9143c0b8efd62ceaf98eaeedf58b8b8c5300f0df780da1")
+		So(result.Header.BlockNum, ShouldEqual, Number(100))
+		So(result.Header.DsBlockNum, ShouldEqual, Number(2))
+		So(result.Header.GasLimit, ShouldEqual, Number(200000))
+		So(result.Header.GasUsed, ShouldEqual, Number(0))
+		So(result.Header.MbInfoHash.String(), ShouldEqual, "db311f58e5c43b043f9143c0b8efd62ceaf98eaeedf58b8b8c5300f0df780da1")
 		So(result.Header.MinerPubKey, ShouldEqual, "0x0238EA7FD93C9E0F30EB8F95BC2B22D7C998D76CFB1620172638B998A4BE01C5F0")
 		So(result.Header.NumMicroBlocks, ShouldEqual, 4)
 		So(result.Header.NumTxns, ShouldEqual, 0)
-		So(result.Header.PrevBlockHash, ShouldEqual, "bb6ba0e008f272037c2fad24965a0b67380885ef1a853fe12d07714357a8f541")
-		So(result.Header.Rewards, ShouldEqual, "0")
-		So(result.Header.StateDeltaHash, ShouldEqual, "0000000000000000000000000000000000000000000000000000000000000000")
-		So(result.Header.StateRootHash, ShouldEqual, "c9065f6fd1520e6ed6174a2ae4c587acdfbd7346fcd4419d483cb5bb7b343ef5")
-		So(result.Header.Timestamp, ShouldEqual, "1549267096666600")
+		So(result.Header.PrevBlockHash.String(), ShouldEqual, "bb6ba0e008f272037c2fad24965a0b67380885ef1a853fe12d07714357a8f541")
+		So(result.Header.Rewards.String(), ShouldEqual, "0")
+		So(result.Header.StateDeltaHash.String(), ShouldEqual, "0000000000000000000000000000000000000000000000000000000000000000")
+		So(result.Header.StateRootHash.String(), ShouldEqual, "c9065f6fd1520e6ed6174a2ae4c587acdfbd7346fcd4419d483cb5bb7b343ef5")
+		So(result.Header.Timestamp.Micros(), ShouldEqual, uint64(1549267096666600))
 		So(result.Header.Version, ShouldEqual, 1)
 	})
+
+	Convey("returns an error if a field of the block is invalid", t, func() {
+		node := newStubNode(func(string, json.RawMessage) (interface{}, string) {
+			return map[string]interface{}{"header": map[string]interface{}{"BlockNum": "100", "GasUsed": "-1"}}, ""
+		})
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).GetTxBlock("100")
+		So(err, ShouldBeError, "invalid integer -1")
+		So(result, ShouldBeNil)
+	})
+}
+
+func TestRPC_GetTxBlockVerbose(t *testing.T) {
+	Convey("returns details of a Transaction block with its co-signature bitmaps", t, func() {
+		var method string
+		node := newStubNode(func(m string, raw json.RawMessage) (interface{}, string) {
+			method = m
+			return map[string]interface{}{
+				"body":   map[string]interface{}{"B1": []bool{true, false, true}, "B2": []bool{false, true, true}, "CS1": "0A", "HeaderSign": "0B"},
+				"header": map[string]interface{}{"BlockNum": "100", "CommitteeHash": "11"},
+			}, ""
+		})
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).GetTxBlockVerbose("100")
+		So(err, ShouldBeNil)
+		So(method, ShouldEqual, "GetTxBlockVerbose")
+		So(result.Body.B1, ShouldResemble, []bool{true, false, true})
+		So(result.Body.B2, ShouldResemble, []bool{false, true, true})
+		So(result.Body.CS1, ShouldEqual, "0A")
+		So(result.Header.CommitteeHash.String(), ShouldEqual, "11")
+	})
 }
 
 func TestRPC_GetLatestTxBlock(t *testing.T) {
 	Convey("returns details of the most recent Transaction block", t, func() {
-		result, err := NewRPC(testNet).GetLatestTxBlock()
+		result, err := newTestRPC().GetLatestTxBlock()
 		So(err, ShouldBeNil)
 		So(result.Body.HeaderSign, ShouldNotBeBlank)
 		So(result.Body.MicroBlockInfos, ShouldNotBeNil)
-		So(result.Header.BlockNum, ShouldNotBeBlank)
-		So(result.Header.DsBlockNum, ShouldNotBeBlank)
-		So(result.Header.GasLimit, ShouldNotBeBlank)
-		So(result.Header.GasUsed, ShouldNotBeBlank)
-		So(result.Header.MbInfoHash, ShouldNotBeBlank)
+		So(result.Header.BlockNum, ShouldBeGreaterThan, 0)
+		So(result.Header.DsBlockNum, ShouldBeGreaterThan, 0)
+		So(result.Header.GasLimit, ShouldBeGreaterThan, 0)
+		So(result.Header.GasUsed, ShouldHaveSameTypeAs, Number(0))
+		So(result.Header.MbInfoHash, ShouldHaveLength, 32)
 		So(result.Header.MinerPubKey, ShouldNotBeBlank)
 		So(result.Header.NumMicroBlocks, ShouldBeGreaterThan, 0)
 		So(result.Header.NumTxns, ShouldHaveSameTypeAs, int64(0))
-		So(result.Header.PrevBlockHash, ShouldNotBeBlank)
-		So(result.Header.Rewards, ShouldNotBeBlank)
-		So(result.Header.StateDeltaHash, ShouldNotBeBlank)
-		So(result.Header.StateRootHash, ShouldNotBeBlank)
-		So(result.Header.Timestamp, ShouldNotBeBlank)
+		So(result.Header.PrevBlockHash, ShouldHaveLength, 32)
+		So(result.Header.Rewards.String(), ShouldNotBeBlank)
+		So(result.Header.StateDeltaHash, ShouldHaveLength, 32)
+		So(result.Header.StateRootHash, ShouldHaveLength, 32)
+		So(result.Header.Timestamp.IsZero(), ShouldBeFalse)
 		So(result.Header.Version, ShouldHaveSameTypeAs, int64(0))
 	})
 }
 
 func TestRPC_GetNumTxBlocks(t *testing.T) {
 	Convey("returns the number of Transaction blocks in the network so far, this is represented as String", t, func() {
-		result, err := NewRPC(testNet).GetNumTxBlocks()
+		result, err := newTestRPC().GetNumTxBlocks()
 		So(err, ShouldBeNil)
 		So(result, ShouldNotBeBlank)
 	})
@@ -165,7 +251,7 @@ func TestRPC_GetNumTxBlocks(t *testing.T) {
 
 func TestRPC_GetTxBlockRate(t *testing.T) {
 	Convey("returns the current Transaction blockrate per second", t, func() {
-		result, err := NewRPC(testNet).GetTxBlockRate()
+		result, err := newTestRPC().GetTxBlockRate()
 		So(err, ShouldBeNil)
 		So(result, ShouldBeGreaterThan, 0)
 	})
@@ -173,7 +259,7 @@ func TestRPC_GetTxBlockRate(t *testing.T) {
 
 func TestRPC_GetTxBlockListing(t *testing.T) {
 	Convey("returns a paginated list of Transaction blocks", t, func() {
-		result, err := NewRPC(testNet).TxBlockListing(1)
+		result, err := newTestRPC().TxBlockListing(1)
 		So(err, ShouldBeNil)
 		So(len(result.Data), ShouldEqual, 10)
 		So(result.MaxPages, ShouldBeGreaterThan, 0)
@@ -182,7 +268,7 @@ func TestRPC_GetTxBlockListing(t *testing.T) {
 
 func TestRPC_GetNumTransactions(t *testing.T) {
 	Convey("returns the number of Transactions validated in the network so far. This is represented as a String", t, func() {
-		result, err := NewRPC(testNet).GetNumTransactions()
+		result, err := newTestRPC().GetNumTransactions()
 		So(err, ShouldBeNil)
 		So(result, ShouldNotBeBlank)
 	})
@@ -190,7 +276,7 @@ func TestRPC_GetNumTransactions(t *testing.T) {
 
 func TestRPC_GetTransactionRate(t *testing.T) {
 	Convey("returns the current Transaction rate of the network", t, func() {
-		result, err := NewRPC(testNet).GetTransactionRate()
+		result, err := newTestRPC().GetTransactionRate()
 		So(err, ShouldBeNil)
 		So(result, ShouldBeGreaterThanOrEqualTo, 0)
 	})
@@ -198,7 +284,7 @@ func TestRPC_GetTransactionRate(t *testing.T) {
 
 func TestRPC_GetCurrentMiniEpoch(t *testing.T) {
 	Convey("returns the number of TX epochs in the network so far represented as String", t, func() {
-		result, err := NewRPC(testNet).GetCurrentMiniEpoch()
+		result, err := newTestRPC().GetCurrentMiniEpoch()
 		So(err, ShouldBeNil)
 		So(result, ShouldNotBeBlank)
 	})
@@ -206,7 +292,7 @@ func TestRPC_GetCurrentMiniEpoch(t *testing.T) {
 
 func TestRPC_GetCurrentDSEpoch(t *testing.T) {
 	Convey("returns the number of DS epochs in the network so far represented as String", t, func() {
-		result, err := NewRPC(testNet).GetCurrentDSEpoch()
+		result, err := newTestRPC().GetCurrentDSEpoch()
 		So(err, ShouldBeNil)
 		So(result, ShouldNotBeBlank)
 	})
@@ -214,7 +300,7 @@ func TestRPC_GetCurrentDSEpoch(t *testing.T) {
 
 func TestRPC_GetPrevDifficulty(t *testing.T) {
 	Convey("returns the minimum shard difficulty of the previous block, this is represented as an Number", t, func() {
-		result, err := NewRPC(testNet).GetPrevDifficulty()
+		result, err := newTestRPC().GetPrevDifficulty()
 		So(err, ShouldBeNil)
 		So(result, ShouldBeGreaterThan, 0)
 	})
@@ -222,7 +308,7 @@ func TestRPC_GetPrevDifficulty(t *testing.T) {
 
 func TestRPC_GetPrevDSDifficulty(t *testing.T) {
 	Convey("returns the minimum DS difficulty of the previous block, this is represented as an Number", t, func() {
-		result, err := NewRPC(testNet).GetPrevDSDifficulty()
+		result, err := newTestRPC().GetPrevDSDifficulty()
 		So(err, ShouldBeNil)
 		So(result, ShouldBeGreaterThan, 0)
 	})
@@ -231,6 +317,7 @@ func TestRPC_GetPrevDSDifficulty(t *testing.T) {
 func TestRPC_CreateTransaction(t *testing.T) {
 	Convey("returns a hash of created Transaction", t, func() {
 		zillean := NewZillean(testNet)
+		zillean.RPC = newTestRPC()
 		privateKey := "B7139607427E6A03436469806FC1167ECEA26130736BDE063A4EED01036DBF03"
 		publicKey, _ := zillean.GetPublicKeyFromPrivateKey(privateKey)
 		rawTx := RawTransaction{
@@ -251,7 +338,7 @@ func TestRPC_CreateTransaction(t *testing.T) {
 
 func TestRPC_GetTransaction(t *testing.T) {
 	Convey("returns details of a Transaction by its hash", t, func() {
-		result, err := NewRPC(testNet).GetTransaction("920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5")
+		result, err := newTestRPC().GetTransaction("920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5")
 		So(err, ShouldBeNil)
 		So(result.ID, ShouldEqual, "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5")
 		So(result.Amount, ShouldEqual, "1000000000000")
@@ -266,11 +353,85 @@ func TestRPC_GetTransaction(t *testing.T) {
 		So(result.ToAddr, ShouldEqual, "df4b175c78e16eebc05173e5c1f87355622d8104")
 		So(result.Version, ShouldEqual, "21823489")
 	})
+
+	Convey("returns the event logs and transitions of a contract call", t, func() {
+		node := newStubNode(newReceiptNode(`{
+			"cumulative_gas": "847",
+			"epoch_num": "171402",
+			"event_logs": [{
+				"_eventname": "TransferSuccess",
+				"address": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f",
+				"params": [
+					{"type": "ByStr20", "value": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b", "vname": "sender"},
+					{"type": "ByStr20", "value": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c", "vname": "recipient"},
+					{"type": "Uint128", "value": "1000000", "vname": "amount"}
+				]
+			}],
+			"success": true,
+			"transitions": [{
+				"accepted": false,
+				"addr": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f",
+				"depth": 0,
+				"msg": {
+					"_amount": "0",
+					"_recipient": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c",
+					"_tag": "RecipientAcceptTransfer",
+					"params": [
+						{"type": "ByStr20", "value": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b", "vname": "sender"},
+						{"type": "ByStr20", "value": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c", "vname": "recipient"},
+						{"type": "Uint128", "value": "1000000", "vname": "amount"}
+					]
+				}
+			}]
+		}`))
+		defer node.Close()
+		result, err := NewRPC(node.URL).GetTransaction(testTxID)
+		So(err, ShouldBeNil)
+		So(result.Receipt.Success, ShouldBeTrue)
+		So(result.Receipt.EventLogs, ShouldHaveLength, 1)
+		So(result.Receipt.EventLogs[0].EventName, ShouldEqual, "TransferSuccess")
+		So(result.Receipt.EventLogs[0].Address, ShouldEqual, "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		amount, ok := result.Receipt.EventLogs[0].Param("amount")
+		So(ok, ShouldBeTrue)
+		So(amount.Type, ShouldEqual, "Uint128")
+		So(amount.String(), ShouldEqual, "1000000")
+		So(result.Receipt.EventLogsByName("TransferSuccess"), ShouldHaveLength, 1)
+		So(result.Receipt.Transitions, ShouldHaveLength, 1)
+		So(result.Receipt.Transitions[0].Addr, ShouldEqual, "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		So(result.Receipt.Transitions[0].Depth, ShouldEqual, 0)
+		So(result.Receipt.Transitions[0].Msg.Tag, ShouldEqual, "RecipientAcceptTransfer")
+		So(result.Receipt.Transitions[0].Msg.Recipient, ShouldEqual, "0x4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		So(result.Receipt.Transitions[0].Msg.Params, ShouldHaveLength, 3)
+		So(result.Receipt.Errors, ShouldBeEmpty)
+	})
+
+	Convey("returns the exceptions and errors of a failed contract call", t, func() {
+		node := newStubNode(newReceiptNode(`{
+			"cumulative_gas": "620",
+			"epoch_num": "171405",
+			"errors": {"0": [7]},
+			"exceptions": [
+				{"line": 87, "message": "Exception thrown: (Message [(_exception : (String \"Error\")) ; (code : (Int32 -2))])"},
+				{"line": 102, "message": "Raised from Transfer"}
+			],
+			"success": false
+		}`))
+		defer node.Close()
+		result, err := NewRPC(node.URL).GetTransaction(testTxID)
+		So(err, ShouldBeNil)
+		So(result.Receipt.Success, ShouldBeFalse)
+		So(result.Receipt.Exceptions, ShouldHaveLength, 2)
+		So(result.Receipt.Exceptions[0].Line, ShouldEqual, 87)
+		So(result.Receipt.Exceptions[1].Message, ShouldEqual, "Raised from Transfer")
+		So(result.Receipt.ErrorsAt(0), ShouldResemble, []ReceiptError{CallContractFailed})
+		So(result.Receipt.ErrorsAt(0)[0].String(), ShouldEqual, "CALL_CONTRACT_FAILED")
+		So(result.Receipt.ErrorsAt(1), ShouldBeEmpty)
+	})
 }
 
 func TestRPC_GetRecentTransactions(t *testing.T) {
 	Convey("returns  the most recent transactions (upto 100) accepted by the specified zilliqa node.", t, func() {
-		result, err := NewRPC(testNet).GetRecentTransactions()
+		result, err := newTestRPC().GetRecentTransactions()
 		So(err, ShouldBeNil)
 		So(len(result.TxnHashes), ShouldEqual, result.Number)
 	})
@@ -278,7 +439,7 @@ func TestRPC_GetRecentTransactions(t *testing.T) {
 
 func TestRPC_GetTransactionsForTxBlock(t *testing.T) {
 	Convey("returns the transactions included within a micro-block created by a specific shard", t, func() {
-		result, err := NewRPC(testNet).GetTransactionsForTxBlock("68317")
+		result, err := newTestRPC().GetTransactionsForTxBlock("68317")
 		So(err, ShouldBeNil)
 		So(len(result), ShouldBeGreaterThan, 0)
 		So(result[1][0], ShouldEqual, "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5")
@@ -287,7 +448,7 @@ func TestRPC_GetTransactionsForTxBlock(t *testing.T) {
 
 func TestRPC_GetNumTxnsTxEpoch(t *testing.T) {
 	Convey("returns the number of transactions in this Transaction epoch, this is represented as String", t, func() {
-		result, err := NewRPC(testNet).GetNumTxnsTxEpoch()
+		result, err := newTestRPC().GetNumTxnsTxEpoch()
 		So(err, ShouldBeNil)
 		So(result, ShouldNotBeBlank)
 	})
@@ -295,7 +456,7 @@ func TestRPC_GetNumTxnsTxEpoch(t *testing.T) {
 
 func TestRPC_GetNumTxnsDSEpoch(t *testing.T) {
 	Convey("returns the number of transactions in this Directory Service epoch, this is represented as String", t, func() {
-		result, err := NewRPC(testNet).GetNumTxnsDSEpoch()
+		result, err := newTestRPC().GetNumTxnsDSEpoch()
 		So(err, ShouldBeNil)
 		So(result, ShouldNotBeBlank)
 	})
@@ -303,7 +464,7 @@ func TestRPC_GetNumTxnsDSEpoch(t *testing.T) {
 
 func TestRPC_GetMinimumGasPrice(t *testing.T) {
 	Convey("returns the minimum gas price of the last DS epoch represented as String", t, func() {
-		result, err := NewRPC(testNet).GetMinimumGasPrice()
+		result, err := newTestRPC().GetMinimumGasPrice()
 		So(err, ShouldBeNil)
 		So(result, ShouldNotBeBlank)
 	})
@@ -311,7 +472,7 @@ func TestRPC_GetMinimumGasPrice(t *testing.T) {
 
 func TestRPC_GetSmartContractCode(t *testing.T) {
 	Convey("returns the Scilla code of a smart contract address", t, func() {
-		result, err := NewRPC(testNet).GetSmartContractCode("6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		result, err := newTestRPC().GetSmartContractCode("6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
 		So(err, ShouldBeNil)
 		So(result, ShouldEqual, "scilla_version 0\n\n    (* HelloWorld contract *)\n\n    import ListUtils\n\n    (***************************************************)\n    (*               Associated library                *)\n    (***************************************************)\n    library HelloWorld\n\n    let one_msg =\n      fun (msg : Message) =>\n      let nil_msg = Nil {Message} in\n      Cons {Message} msg nil_msg\n\n    let not_owner_code = Int32 1\n    let set_hello_code = Int32 2\n\n    (***************************************************)\n    (*             The contract definition             *)\n    (***************************************************)\n\n    contract HelloWorld\n    (owner: ByStr20)\n\n    field welcome_msg : String = \"\"\n\n    transition setHello (msg : String)\n      is_owner = builtin eq owner _sender;\n      match is_owner with\n      | False =>\n        msg = {_tag : \"Main\"; _recipient : _sender; _amount : Uint128 0; code : not_owner_code};\n        msgs = one_msg msg;\n        send msgs\n      | True =>\n        welcome_msg := msg;\n        msg = {_tag : \"Main\"; _recipient : _sender; _amount : Uint128 0; code : set_hello_code};\n        msgs = one_msg msg;\n        send msgs\n      end\n    end\n\n\n    transition getHello ()\n        r <- welcome_msg;\n        e = {_eventname: \"getHello()\"; msg: r};\n        event e\n    end")
 	})
@@ -319,7 +480,7 @@ func TestRPC_GetSmartContractCode(t *testing.T) {
 
 func TestRPC_GetSmartContractInit(t *testing.T) {
 	Convey("returns the initialization parameters (immutable) of a given smart contract address", t, func() {
-		result, err := NewRPC(testNet).GetSmartContractInit("6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		result, err := newTestRPC().GetSmartContractInit("6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
 		So(err, ShouldBeNil)
 		So(len(result), ShouldEqual, 4)
 		So(result[0].Type, ShouldEqual, "Uint32")
@@ -339,7 +500,7 @@ func TestRPC_GetSmartContractInit(t *testing.T) {
 
 func TestRPC_GetSmartContractState(t *testing.T) {
 	Convey("returns the state variables (mutable) of a smart contract address", t, func() {
-		result, err := NewRPC(testNet).GetSmartContractState("6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		result, err := newTestRPC().GetSmartContractState("6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
 		So(err, ShouldBeNil)
 		So(len(result), ShouldEqual, 2)
 		So(result[0].Type, ShouldEqual, "String")
@@ -348,12 +509,47 @@ func TestRPC_GetSmartContractState(t *testing.T) {
 		So(result[1].Type, ShouldEqual, "Uint128")
 		So(result[1].Value, ShouldEqual, "0")
 		So(result[1].Vname, ShouldEqual, "_balance")
+
+		var state struct {
+			WelcomeMsg string   `scilla:"welcome_msg"`
+			Balance    *big.Int `scilla:"_balance"`
+		}
+		So(Unmarshal(result, &state), ShouldBeNil)
+		So(state.WelcomeMsg, ShouldEqual, "Hello World")
+		So(state.Balance.Sign(), ShouldEqual, 0)
+	})
+}
+
+func TestRPC_GetSmartContractSubState(t *testing.T) {
+	Convey("returns a state variable of a smart contract address at indices", t, func() {
+		var params []interface{}
+		node := newStubNode(func(method string, raw json.RawMessage) (interface{}, string) {
+			json.Unmarshal(raw, &params)
+			return map[string]interface{}{"balances": map[string]string{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b": "900"}}, ""
+		})
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).GetSmartContractSubState("6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "balances", []string{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"})
+		So(err, ShouldBeNil)
+		So(params, ShouldResemble, []interface{}{"6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "balances", []interface{}{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"}})
+		So(string(result["balances"]), ShouldEqual, `{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b":"900"}`)
+	})
+
+	Convey("returns nil when the variable does not exist", t, func() {
+		node := newStubNode(func(method string, raw json.RawMessage) (interface{}, string) {
+			return nil, ""
+		})
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).GetSmartContractSubState("6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "missing", nil)
+		So(err, ShouldBeNil)
+		So(result, ShouldBeNil)
 	})
 }
 
 func TestRPC_GetSmartContracts(t *testing.T) {
 	Convey("returns the list of smart contracts created by an address", t, func() {
-		result, err := NewRPC(testNet).GetSmartContracts("f49f1306bc8fb0cd8167a58a3550c1443072e96b")
+		result, err := newTestRPC().GetSmartContracts("f49f1306bc8fb0cd8167a58a3550c1443072e96b")
 		So(err, ShouldBeNil)
 		So(len(result), ShouldEqual, 1)
 		So(result[0].Address, ShouldEqual, "6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
@@ -368,7 +564,7 @@ func TestRPC_GetSmartContracts(t *testing.T) {
 
 func TestRPC_GetContractAddressFromTransactionID(t *testing.T) {
 	Convey("returns a smart contract address of 20 bytes from a transaction ID, represented as a String", t, func() {
-		result, err := NewRPC(testNet).GetContractAddressFromTransactionID("1088aa52939a6d6d79deb80557f75d00b9e8615864df906625dc9e6948b9be95")
+		result, err := newTestRPC().GetContractAddressFromTransactionID("1088aa52939a6d6d79deb80557f75d00b9e8615864df906625dc9e6948b9be95")
 		So(err, ShouldBeNil)
 		So(result, ShouldEqual, "6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
 	})
@@ -376,7 +572,7 @@ func TestRPC_GetContractAddressFromTransactionID(t *testing.T) {
 
 func TestRPC_GetBalance(t *testing.T) {
 	Convey("returns the balance and nonce of a given address", t, func() {
-		result, err := NewRPC(testNet).GetBalance("Df4B175C78e16EeBC05173E5C1f87355622D8104")
+		result, err := newTestRPC().GetBalance("Df4B175C78e16EeBC05173E5C1f87355622D8104")
 		So(err, ShouldBeNil)
 		So(result.Balance, ShouldEqual, "3000000000000")
 		So(result.Nonce, ShouldEqual, 0)
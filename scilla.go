@@ -0,0 +1,368 @@
+package zillean
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var byStrPattern = regexp.MustCompile(`^([0-9a-f]{2})+$`)
+
+// Value represents a typed Scilla value, which is encoded into the Scilla JSON format.
+type Value interface {
+	json.Marshaler
+	// Type returns the Scilla type of the value.
+	Type() string
+	// Validate checks whether the value is a valid value of its type.
+	Validate() error
+}
+
+// Param describes a named Scilla value, such as an init parameter of a contract.
+type Param struct {
+	Vname string
+	Value Value
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p Param) MarshalJSON() ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	value, err := p.Value.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Vname string          `json:"vname"`
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}{p.Vname, p.Value.Type(), value})
+}
+
+// Validate checks whether the param has a name and a valid value.
+func (p Param) Validate() error {
+	if p.Vname == "" {
+		return errors.New("missing param name")
+	}
+	if p.Value == nil {
+		return fmt.Errorf("missing value of %s", p.Vname)
+	}
+	if err := p.Value.Validate(); err != nil {
+		return fmt.Errorf("invalid value of %s: %v", p.Vname, err)
+	}
+	return nil
+}
+
+type intValue struct {
+	typ string
+	n   *big.Int
+}
+
+func (v intValue) Type() string { return v.typ }
+
+func (v intValue) MarshalJSON() ([]byte, error) {
+	if v.n == nil {
+		return nil, fmt.Errorf("missing %s value", v.typ)
+	}
+	return json.Marshal(v.n.String())
+}
+
+func (v intValue) Validate() error {
+	if v.n == nil {
+		return fmt.Errorf("missing %s value", v.typ)
+	}
+	min, max := intRange(v.typ)
+	if v.n.Cmp(min) < 0 || v.n.Cmp(max) > 0 {
+		return fmt.Errorf("%s out of range for %s", v.n, v.typ)
+	}
+	return nil
+}
+
+// intRange returns the inclusive bounds of a Scilla integer type. Scilla does not bound BNum, which is
+// capped at 2^256 here.
+func intRange(typ string) (*big.Int, *big.Int) {
+	if typ == "BNum" {
+		return big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), 256)
+	}
+	signed := strings.HasPrefix(typ, "Int")
+	bits, _ := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "Uint"), "Int"))
+	if signed {
+		max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)), big.NewInt(1))
+		min := new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)))
+		return min, max
+	}
+	return big.NewInt(0), new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+}
+
+// Uint32 returns a Scilla Uint32 value.
+func Uint32(n uint32) Value { return intValue{"Uint32", new(big.Int).SetUint64(uint64(n))} }
+
+// Uint64 returns a Scilla Uint64 value.
+func Uint64(n uint64) Value { return intValue{"Uint64", new(big.Int).SetUint64(n)} }
+
+// Uint128 returns a Scilla Uint128 value.
+func Uint128(n *big.Int) Value { return intValue{"Uint128", n} }
+
+// Uint256 returns a Scilla Uint256 value.
+func Uint256(n *big.Int) Value { return intValue{"Uint256", n} }
+
+// Int32 returns a Scilla Int32 value.
+func Int32(n int32) Value { return intValue{"Int32", big.NewInt(int64(n))} }
+
+// Int64 returns a Scilla Int64 value.
+func Int64(n int64) Value { return intValue{"Int64", big.NewInt(n)} }
+
+// Int128 returns a Scilla Int128 value.
+func Int128(n *big.Int) Value { return intValue{"Int128", n} }
+
+// Int256 returns a Scilla Int256 value.
+func Int256(n *big.Int) Value { return intValue{"Int256", n} }
+
+// BNum returns a Scilla BNum (block number) value.
+func BNum(n uint64) Value { return intValue{"BNum", new(big.Int).SetUint64(n)} }
+
+type stringValue string
+
+func (v stringValue) Type() string { return "String" }
+
+func (v stringValue) MarshalJSON() ([]byte, error) { return json.Marshal(string(v)) }
+
+func (v stringValue) Validate() error { return nil }
+
+// String returns a Scilla String value.
+func String(s string) Value { return stringValue(s) }
+
+type byStrValue struct {
+	typ string
+	hex string
+}
+
+func (v byStrValue) Type() string { return v.typ }
+
+func (v byStrValue) MarshalJSON() ([]byte, error) { return json.Marshal("0x" + v.hex) }
+
+func (v byStrValue) Validate() error {
+	if !byStrPattern.MatchString(v.hex) {
+		return fmt.Errorf("invalid %s: %s", v.typ, v.hex)
+	}
+	if fmt.Sprintf("ByStr%d", len(v.hex)/2) != v.typ {
+		return fmt.Errorf("invalid length for %s: %s", v.typ, v.hex)
+	}
+	return nil
+}
+
+// ByStr20 returns a Scilla ByStr20 value, which is used for addresses. The 0x prefix is optional.
+func ByStr20(address string) Value {
+	return byStrValue{"ByStr20", strings.ToLower(strings.TrimPrefix(address, "0x"))}
+}
+
+// ByStr returns a Scilla ByStrX value of a hex string, where X is the number of bytes. The 0x prefix is optional.
+func ByStr(hex string) Value {
+	hex = strings.ToLower(strings.TrimPrefix(hex, "0x"))
+	return byStrValue{fmt.Sprintf("ByStr%d", len(hex)/2), hex}
+}
+
+type adtValue struct {
+	typ         string
+	constructor string
+	argTypes    []string
+	args        []Value
+}
+
+func (v adtValue) Type() string { return v.typ }
+
+func (v adtValue) MarshalJSON() ([]byte, error) {
+	argTypes := v.argTypes
+	if argTypes == nil {
+		argTypes = []string{}
+	}
+	args := v.args
+	if args == nil {
+		args = []Value{}
+	}
+	return json.Marshal(struct {
+		Constructor string   `json:"constructor"`
+		ArgTypes    []string `json:"argtypes"`
+		Arguments   []Value  `json:"arguments"`
+	}{v.constructor, argTypes, args})
+}
+
+func (v adtValue) Validate() error {
+	if v.constructor == "" {
+		return fmt.Errorf("missing constructor of %s", v.typ)
+	}
+	for _, arg := range v.args {
+		if arg == nil {
+			return fmt.Errorf("missing argument of %s", v.constructor)
+		}
+		if err := arg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// The constructors of the builtin ADTs are checked against their type arguments.
+	var expected []string
+	switch v.constructor {
+	case "True", "False", "None", "Nil":
+	case "Some":
+		expected = v.argTypes
+	case "Pair":
+		expected = v.argTypes
+	case "Cons":
+		if len(v.argTypes) == 1 {
+			expected = []string{v.argTypes[0], applyType("List", v.argTypes[0])}
+		}
+	default:
+		return nil
+	}
+	if len(v.args) != len(expected) {
+		return fmt.Errorf("%s takes %d arguments, got %d", v.constructor, len(expected), len(v.args))
+	}
+	for i, arg := range v.args {
+		if arg.Type() != expected[i] {
+			return fmt.Errorf("argument %d of %s must be %s, got %s", i, v.constructor, expected[i], arg.Type())
+		}
+	}
+	return nil
+}
+
+// ADT returns a value of a Scilla algebraic data type, such as a user-defined ADT of a contract.
+// typeName and typeArgs form the type, e.g. "Option" and ["Uint128"] for Option (Uint128).
+func ADT(typeName string, typeArgs []string, constructor string, args ...Value) Value {
+	return adtValue{applyType(typeName, typeArgs...), constructor, typeArgs, args}
+}
+
+// Bool returns a Scilla Bool value.
+func Bool(b bool) Value {
+	if b {
+		return ADT("Bool", nil, "True")
+	}
+	return ADT("Bool", nil, "False")
+}
+
+// Some returns a Scilla Option value holding a value.
+func Some(v Value) Value {
+	if v == nil {
+		return ADT("Option", nil, "Some", v)
+	}
+	return ADT("Option", []string{v.Type()}, "Some", v)
+}
+
+// None returns an empty Scilla Option value of a given type.
+func None(typ string) Value {
+	return ADT("Option", []string{typ}, "None")
+}
+
+// Pair returns a Scilla Pair value.
+func Pair(first, second Value) Value {
+	if first == nil || second == nil {
+		return ADT("Pair", nil, "Pair", first, second)
+	}
+	return ADT("Pair", []string{first.Type(), second.Type()}, "Pair", first, second)
+}
+
+type listValue struct {
+	elemType string
+	elems    []Value
+}
+
+func (v listValue) Type() string { return applyType("List", v.elemType) }
+
+func (v listValue) MarshalJSON() ([]byte, error) {
+	elems := v.elems
+	if elems == nil {
+		elems = []Value{}
+	}
+	return json.Marshal(elems)
+}
+
+func (v listValue) Validate() error {
+	for i, elem := range v.elems {
+		if elem == nil {
+			return fmt.Errorf("missing element %d of %s", i, v.Type())
+		}
+		if elem.Type() != v.elemType {
+			return fmt.Errorf("element %d of %s must be %s, got %s", i, v.Type(), v.elemType, elem.Type())
+		}
+		if err := elem.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns a Scilla List value whose elements are of elemType.
+func List(elemType string, elems ...Value) Value {
+	return listValue{elemType, elems}
+}
+
+// MapEntry describes a key-value pair in a Scilla Map.
+type MapEntry struct {
+	Key Value
+	Val Value
+}
+
+type mapValue struct {
+	keyType string
+	valType string
+	entries []MapEntry
+}
+
+func (v mapValue) Type() string { return applyType("Map", v.keyType, v.valType) }
+
+func (v mapValue) MarshalJSON() ([]byte, error) {
+	type entry struct {
+		Key Value `json:"key"`
+		Val Value `json:"val"`
+	}
+	entries := make([]entry, len(v.entries))
+	for i, e := range v.entries {
+		entries[i] = entry{e.Key, e.Val}
+	}
+	return json.Marshal(entries)
+}
+
+func (v mapValue) Validate() error {
+	keys := map[string]bool{}
+	for i, e := range v.entries {
+		if e.Key == nil || e.Val == nil {
+			return fmt.Errorf("missing key or value of entry %d of %s", i, v.Type())
+		}
+		if e.Key.Type() != v.keyType {
+			return fmt.Errorf("key %d of %s must be %s, got %s", i, v.Type(), v.keyType, e.Key.Type())
+		}
+		if e.Val.Type() != v.valType {
+			return fmt.Errorf("value %d of %s must be %s, got %s", i, v.Type(), v.valType, e.Val.Type())
+		}
+		if err := e.Key.Validate(); err != nil {
+			return err
+		}
+		if err := e.Val.Validate(); err != nil {
+			return err
+		}
+		key, _ := e.Key.MarshalJSON()
+		if keys[string(key)] {
+			return fmt.Errorf("duplicate key %s in %s", key, v.Type())
+		}
+		keys[string(key)] = true
+	}
+	return nil
+}
+
+// Map returns a Scilla Map value from keyType to valType.
+func Map(keyType, valType string, entries ...MapEntry) Value {
+	return mapValue{keyType, valType, entries}
+}
+
+// applyType returns a Scilla type applied to type arguments, e.g. Map (ByStr20) (Uint128).
+func applyType(name string, args ...string) string {
+	typ := name
+	for _, arg := range args {
+		typ += " (" + arg + ")"
+	}
+	return typ
+}
@@ -0,0 +1,439 @@
+package zillean
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// ContractInterface describes the interface of a Scilla contract: its immutable parameters,
+// mutable fields and transitions.
+type ContractInterface struct {
+	Name          string
+	ScillaVersion uint32
+	Params        []VarDef
+	Fields        []VarDef
+	Transitions   []TransitionDef
+}
+
+// VarDef describes a named and typed variable of a contract.
+// Type is normalized to the form used in the Scilla JSON, e.g. Map (ByStr20) (Uint128).
+type VarDef struct {
+	Name string
+	Type string
+}
+
+// TransitionDef describes a transition and its parameters.
+type TransitionDef struct {
+	Name   string
+	Params []VarDef
+}
+
+// ParseContract extracts the interface of a contract from Scilla source code.
+func ParseContract(code string) (*ContractInterface, error) {
+	scillaVersion, err := parseScillaVersion(code)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenizeScilla(code)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &scillaParser{tokens: tokens}
+	if !p.skipToContract() {
+		return nil, errors.New("missing contract definition")
+	}
+	c := &ContractInterface{ScillaVersion: scillaVersion}
+	if c.Name, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if c.Params, err = p.varDefs(); err != nil {
+		return nil, fmt.Errorf("contract %s: %v", c.Name, err)
+	}
+
+	for !p.done() {
+		switch p.next() {
+		case "field":
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, fmt.Errorf("field %s: %v", name, err)
+			}
+			typ, err := p.typ("=")
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", name, err)
+			}
+			c.Fields = append(c.Fields, VarDef{name, typ})
+		case "transition":
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			params, err := p.varDefs()
+			if err != nil {
+				return nil, fmt.Errorf("transition %s: %v", name, err)
+			}
+			if !p.skipToEnd() {
+				return nil, fmt.Errorf("transition %s: missing end", name)
+			}
+			c.Transitions = append(c.Transitions, TransitionDef{name, params})
+		case "procedure":
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.varDefs(); err != nil {
+				return nil, fmt.Errorf("procedure %s: %v", name, err)
+			}
+			if !p.skipToEnd() {
+				return nil, fmt.Errorf("procedure %s: missing end", name)
+			}
+		case "with":
+			// A match expression or an address type in the initial value of a field.
+			p.skipToEnd()
+		}
+	}
+
+	return c, nil
+}
+
+// Field returns the field with a given name.
+func (c *ContractInterface) Field(name string) (VarDef, bool) {
+	for _, field := range c.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return VarDef{}, false
+}
+
+// Transition returns the transition with a given name.
+func (c *ContractInterface) Transition(name string) (TransitionDef, bool) {
+	for _, transition := range c.Transitions {
+		if transition.Name == name {
+			return transition, true
+		}
+	}
+	return TransitionDef{}, false
+}
+
+// ValidateInit checks whether params match the immutable parameters of the contract.
+// The implicit _scilla_version parameter may be omitted.
+func (c *ContractInterface) ValidateInit(params []Param) error {
+	defs := c.Params
+	if hasParam(params, "_scilla_version") {
+		defs = append([]VarDef{{"_scilla_version", "Uint32"}}, defs...)
+	}
+	if err := validateParams(defs, params); err != nil {
+		return fmt.Errorf("contract %s: %v", c.Name, err)
+	}
+	return nil
+}
+
+// ValidateCall checks whether a transition exists and params match its parameters.
+func (c *ContractInterface) ValidateCall(transition string, params []Param) error {
+	t, ok := c.Transition(transition)
+	if !ok {
+		return fmt.Errorf("contract %s has no transition %s", c.Name, transition)
+	}
+	if err := validateParams(t.Params, params); err != nil {
+		return fmt.Errorf("transition %s: %v", transition, err)
+	}
+	return nil
+}
+
+func validateParams(defs []VarDef, params []Param) error {
+	given := map[string]Param{}
+	for _, param := range params {
+		if err := param.Validate(); err != nil {
+			return err
+		}
+		given[param.Vname] = param
+	}
+	declared := map[string]bool{}
+	for _, def := range defs {
+		param, ok := given[def.Name]
+		if !ok {
+			return fmt.Errorf("missing param %s", def.Name)
+		}
+		if param.Value.Type() != def.Type {
+			return fmt.Errorf("param %s must be %s, got %s", def.Name, def.Type, param.Value.Type())
+		}
+		declared[def.Name] = true
+	}
+	for _, param := range params {
+		if !declared[param.Vname] {
+			return fmt.Errorf("unknown param %s", param.Vname)
+		}
+	}
+	return nil
+}
+
+type scillaParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *scillaParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *scillaParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *scillaParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// skipToContract skips the library up to and including the contract keyword, which also appears in address
+// types such as ByStr20 with contract field x : Uint128 end.
+func (p *scillaParser) skipToContract() bool {
+	for !p.done() {
+		switch p.next() {
+		case "contract":
+			return true
+		case "with":
+			p.skipToEnd()
+		}
+	}
+	return false
+}
+
+// skipToEnd skips up to and including the end which closes the current block, such as the body of a transition,
+// skipping the with ... end of match expressions and address types inside it.
+func (p *scillaParser) skipToEnd() bool {
+	depth := 0
+	for !p.done() {
+		switch p.next() {
+		case "with":
+			depth++
+		case "end":
+			if depth == 0 {
+				return true
+			}
+			depth--
+		}
+	}
+	return false
+}
+
+func (p *scillaParser) expect(token string) error {
+	if got := p.next(); got != token {
+		return fmt.Errorf("expected %q, got %q", token, got)
+	}
+	return nil
+}
+
+func (p *scillaParser) ident() (string, error) {
+	token := p.next()
+	if token == "" || !isIdentStart(rune(token[0])) {
+		return "", fmt.Errorf("expected an identifier, got %q", token)
+	}
+	return token, nil
+}
+
+// varDefs parses a parenthesized list of typed variables, e.g. (to : ByStr20, amount : Uint128).
+func (p *scillaParser) varDefs() ([]VarDef, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var defs []VarDef
+	if p.peek() == ")" {
+		p.next()
+		return defs, nil
+	}
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, fmt.Errorf("param %s: %v", name, err)
+		}
+		typ, err := p.typ(",", ")")
+		if err != nil {
+			return nil, fmt.Errorf("param %s: %v", name, err)
+		}
+		defs = append(defs, VarDef{name, typ})
+		if p.next() == ")" {
+			return defs, nil
+		}
+	}
+}
+
+// typ parses a type up to one of the terminators at the top level, leaving the terminator unconsumed.
+func (p *scillaParser) typ(terminators ...string) (string, error) {
+	var tokens []string
+	depth := 0
+	for {
+		token := p.peek()
+		if token == "" {
+			return "", errors.New("unexpected end of code in type")
+		}
+		if depth == 0 {
+			for _, terminator := range terminators {
+				if token == terminator {
+					return normalizeType(tokens)
+				}
+			}
+		}
+		switch token {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "with":
+			// Address types such as ByStr20 with contract ... end are represented as ByStr20.
+			p.next()
+			if !p.skipToEnd() {
+				return "", errors.New("unexpected end of code in type")
+			}
+			continue
+		}
+		tokens = append(tokens, p.next())
+	}
+}
+
+// normalizeType returns the Scilla JSON form of a type, e.g. Map ByStr20 (List Uint128) becomes
+// Map (ByStr20) (List (Uint128)).
+func normalizeType(tokens []string) (string, error) {
+	typ, rest, err := parseType(tokens)
+	if err != nil {
+		return "", err
+	}
+	if len(rest) > 0 {
+		return "", fmt.Errorf("unexpected %q in type", rest[0])
+	}
+	return typ, nil
+}
+
+// parseType parses a type application or a function type and returns the remaining tokens.
+func parseType(tokens []string) (string, []string, error) {
+	head, group, rest, err := parseTypeAtom(tokens)
+	if err != nil {
+		return "", nil, err
+	}
+	var args []string
+	for len(rest) > 0 && rest[0] != ")" && rest[0] != "->" {
+		var arg string
+		arg, _, rest, err = parseTypeAtom(rest)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, arg)
+	}
+	if group && len(args) > 0 {
+		return "", nil, fmt.Errorf("unexpected type arguments of (%s)", head)
+	}
+
+	typ := applyType(head, args...)
+	if len(rest) > 0 && rest[0] == "->" {
+		result, rest, err := parseType(rest[1:])
+		if err != nil {
+			return "", nil, err
+		}
+		return typ + " -> " + result, rest, nil
+	}
+	return typ, rest, nil
+}
+
+// parseTypeAtom parses a type name or a parenthesized type, reporting which one it was.
+func parseTypeAtom(tokens []string) (string, bool, []string, error) {
+	if len(tokens) == 0 {
+		return "", false, nil, errors.New("missing type")
+	}
+	if tokens[0] != "(" {
+		if !isIdentStart(rune(tokens[0][0])) {
+			return "", false, nil, fmt.Errorf("unexpected %q in type", tokens[0])
+		}
+		return tokens[0], false, tokens[1:], nil
+	}
+	typ, rest, err := parseType(tokens[1:])
+	if err != nil {
+		return "", false, nil, err
+	}
+	if len(rest) == 0 || rest[0] != ")" {
+		return "", false, nil, errors.New("unbalanced parentheses in type")
+	}
+	return typ, true, rest[1:], nil
+}
+
+// tokenizeScilla splits Scilla source code into tokens, dropping comments and whitespace.
+func tokenizeScilla(code string) ([]string, error) {
+	var tokens []string
+	src := []rune(code)
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' && i+1 < len(src) && src[i+1] == '*':
+			depth := 0
+			for ; i < len(src); i++ {
+				if src[i] == '(' && i+1 < len(src) && src[i+1] == '*' {
+					depth++
+					i++
+				} else if src[i] == '*' && i+1 < len(src) && src[i+1] == ')' {
+					depth--
+					i++
+					if depth == 0 {
+						i++
+						break
+					}
+				}
+			}
+			if depth != 0 {
+				return nil, errors.New("unterminated comment")
+			}
+		case c == '"':
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(src) {
+				return nil, errors.New("unterminated string literal")
+			}
+			tokens = append(tokens, string(src[i:j+1]))
+			i = j + 1
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && (isIdentStart(src[j]) || unicode.IsDigit(src[j]) || src[j] == '\'' || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, string(src[i:j]))
+			i = j
+		case unicode.IsDigit(c):
+			j := i + 1
+			for j < len(src) && (unicode.IsLetter(src[j]) || unicode.IsDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, string(src[i:j]))
+			i = j
+		default:
+			if i+1 < len(src) {
+				switch op := string(src[i : i+2]); op {
+				case "=>", "->", ":=", "<-":
+					tokens = append(tokens, op)
+					i += 2
+					continue
+				}
+			}
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentStart(c rune) bool {
+	return unicode.IsLetter(c) || c == '_'
+}
@@ -0,0 +1,173 @@
+package zillean
+
+import (
+	"math/big"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const fungibleTokenCode = `scilla_version 0
+
+(***************************************************)
+(*               Associated library                *)
+(***************************************************)
+import BoolUtils IntUtils
+
+library FungibleToken
+
+let one_msg =
+  fun (msg : Message) =>
+  let nil_msg = Nil {Message} in
+  Cons {Message} msg nil_msg
+
+type Error =
+  | CodeNotOwner
+  | CodeInsufficientFunds
+
+(* "contract" and "transition" inside comments (* even nested ones *) are ignored *)
+contract FungibleToken
+(
+  contract_owner: ByStr20,
+  name : String,
+  symbol: String,
+  decimals: Uint32,
+  init_supply : Uint128,
+  default_operators : List ByStr20
+)
+
+field total_supply : Uint128 = init_supply
+field balances: Map ByStr20 Uint128
+  = let emp_map = Emp ByStr20 Uint128 in
+    builtin put emp_map contract_owner init_supply
+field allowances: Map ByStr20 (Map ByStr20 Uint128) = Emp ByStr20 (Map ByStr20 Uint128)
+field pending : Option (Pair ByStr20 Uint128) = None {(Pair ByStr20 Uint128)}
+
+procedure ThrowError(err : Error)
+  e = { _exception : "transition Fake(x : Uint32)" };
+  throw e
+end
+
+transition Transfer(to: ByStr20, amount: Uint128)
+  bal <- balances[_sender];
+  match bal with
+  | Some b => e = {_eventname : "TransferSuccess"; sender : _sender; recipient : to; amount : amount}; event e
+  | None => err = CodeInsufficientFunds; ThrowError err
+  end
+end
+
+transition BatchTransfer (to_list : List (Pair (ByStr20 with end) Uint128))
+end
+
+transition Pause()
+end
+`
+
+func TestParseContract(t *testing.T) {
+	Convey("returns the interface of the contract", t, func() {
+		c, err := ParseContract(fungibleTokenCode)
+		So(err, ShouldBeNil)
+		So(c.Name, ShouldEqual, "FungibleToken")
+		So(c.ScillaVersion, ShouldEqual, 0)
+		So(c.Params, ShouldResemble, []VarDef{
+			{"contract_owner", "ByStr20"},
+			{"name", "String"},
+			{"symbol", "String"},
+			{"decimals", "Uint32"},
+			{"init_supply", "Uint128"},
+			{"default_operators", "List (ByStr20)"},
+		})
+		So(c.Fields, ShouldResemble, []VarDef{
+			{"total_supply", "Uint128"},
+			{"balances", "Map (ByStr20) (Uint128)"},
+			{"allowances", "Map (ByStr20) (Map (ByStr20) (Uint128))"},
+			{"pending", "Option (Pair (ByStr20) (Uint128))"},
+		})
+		So(c.Transitions, ShouldResemble, []TransitionDef{
+			{"Transfer", []VarDef{{"to", "ByStr20"}, {"amount", "Uint128"}}},
+			{"BatchTransfer", []VarDef{{"to_list", "List (Pair (ByStr20) (Uint128))"}}},
+			{"Pause", nil},
+		})
+		field, ok := c.Field("balances")
+		So(ok, ShouldBeTrue)
+		So(field.Type, ShouldEqual, "Map (ByStr20) (Uint128)")
+	})
+
+	Convey("skips address types in the library, procedures and field values", t, func() {
+		c, err := ParseContract(`scilla_version 0
+library Wallet
+let balance_of =
+  fun (a : ByStr20 with contract field x : Uint128 end) =>
+  a
+
+contract Wallet (owner : ByStr20 with contract field admins : List (ByStr20 with end) end)
+
+field counters : Map ByStr20 Uint32 = Emp ByStr20 Uint32
+field target : Option (ByStr20 with contract field y : Uint32 end) = None {(ByStr20 with end)}
+
+procedure Check (c : ByStr20 with contract field y : Uint32 end)
+  match c with
+  | _ => y <- & c.y
+  end
+end
+
+transition Update (c : ByStr20 with contract field y : Uint32 end, field_count : Uint32)
+  Check c
+end
+`)
+		So(err, ShouldBeNil)
+		So(c.Name, ShouldEqual, "Wallet")
+		So(c.Params, ShouldResemble, []VarDef{{"owner", "ByStr20"}})
+		So(c.Fields, ShouldResemble, []VarDef{
+			{"counters", "Map (ByStr20) (Uint32)"},
+			{"target", "Option (ByStr20)"},
+		})
+		So(c.Transitions, ShouldResemble, []TransitionDef{
+			{"Update", []VarDef{{"c", "ByStr20"}, {"field_count", "Uint32"}}},
+		})
+	})
+
+	Convey("returns an error when the code has no contract", t, func() {
+		_, err := ParseContract("scilla_version 0\nlibrary Empty")
+		So(err.Error(), ShouldEqual, "missing contract definition")
+	})
+
+	Convey("returns an error when a comment is not terminated", t, func() {
+		_, err := ParseContract("contract A () (* field")
+		So(err.Error(), ShouldEqual, "unterminated comment")
+	})
+}
+
+func TestContractInterface_ValidateCall(t *testing.T) {
+	c, _ := ParseContract(fungibleTokenCode)
+
+	Convey("accepts params matching the transition", t, func() {
+		So(c.ValidateCall("Transfer", []Param{
+			{"to", ByStr20("4baf5fada8e5db92c3d3242618c5b47133ae003c")},
+			{"amount", Uint128(big.NewInt(1))},
+		}), ShouldBeNil)
+		So(c.ValidateCall("BatchTransfer", []Param{
+			{"to_list", List("Pair (ByStr20) (Uint128)", Pair(ByStr20("4baf5fada8e5db92c3d3242618c5b47133ae003c"), Uint128(big.NewInt(1))))},
+		}), ShouldBeNil)
+		So(c.ValidateCall("Pause", nil), ShouldBeNil)
+	})
+
+	Convey("returns an error when the call does not match the transition", t, func() {
+		So(c.ValidateCall("Mint", nil).Error(), ShouldEqual, "contract FungibleToken has no transition Mint")
+		So(c.ValidateCall("Transfer", []Param{{"to", ByStr20("4baf5fada8e5db92c3d3242618c5b47133ae003c")}}).Error(), ShouldEqual, "transition Transfer: missing param amount")
+		So(c.ValidateCall("Transfer", []Param{
+			{"to", ByStr20("4baf5fada8e5db92c3d3242618c5b47133ae003c")},
+			{"amount", Uint32(1)},
+		}).Error(), ShouldEqual, "transition Transfer: param amount must be Uint128, got Uint32")
+		So(c.ValidateCall("Pause", []Param{{"now", BNum(1)}}).Error(), ShouldEqual, "transition Pause: unknown param now")
+	})
+}
+
+func TestContractInterface_ValidateInit(t *testing.T) {
+	Convey("accepts init params with or without _scilla_version", t, func() {
+		c, _ := ParseContract(helloWorldCode)
+		So(c.ValidateInit([]Param{{"owner", ByStr20("4baf5fada8e5db92c3d3242618c5b47133ae003c")}}), ShouldBeNil)
+		So(c.ValidateInit([]Param{{"_scilla_version", Uint32(0)}, {"owner", ByStr20("4baf5fada8e5db92c3d3242618c5b47133ae003c")}}), ShouldBeNil)
+		So(c.ValidateInit(nil), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,82 @@
+package zillean
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParam_MarshalJSON(t *testing.T) {
+	Convey("returns the Scilla JSON encoding of a named value", t, func() {
+		amount, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+		params := []Param{
+			{"_scilla_version", Uint32(0)},
+			{"owner", ByStr20("0xF49F1306BC8FB0CD8167A58A3550C1443072E96B")},
+			{"name", String("Zillean")},
+			{"supply", Uint128(amount)},
+			{"delta", Int32(-2)},
+			{"deadline", BNum(73628)},
+			{"hash", ByStr("0x0f00e9d3175300fc287812d201edcfbfcb8165809606545595bf53700c524648")},
+		}
+		data, err := json.Marshal(params)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `[{"vname":"_scilla_version","type":"Uint32","value":"0"},`+
+			`{"vname":"owner","type":"ByStr20","value":"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},`+
+			`{"vname":"name","type":"String","value":"Zillean"},`+
+			`{"vname":"supply","type":"Uint128","value":"340282366920938463463374607431768211455"},`+
+			`{"vname":"delta","type":"Int32","value":"-2"},`+
+			`{"vname":"deadline","type":"BNum","value":"73628"},`+
+			`{"vname":"hash","type":"ByStr32","value":"0x0f00e9d3175300fc287812d201edcfbfcb8165809606545595bf53700c524648"}]`)
+	})
+
+	Convey("returns an error when the value is missing", t, func() {
+		_, err := json.Marshal(Param{"supply", Uint128(nil)})
+		So(err, ShouldNotBeNil)
+		_, err = json.Marshal(Param{Vname: "owner"})
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestValue_MarshalJSON(t *testing.T) {
+	Convey("returns the Scilla JSON encoding of ADTs, lists and maps", t, func() {
+		cases := []struct {
+			value Value
+			typ   string
+			json  string
+		}{
+			{Bool(true), "Bool", `{"constructor":"True","argtypes":[],"arguments":[]}`},
+			{Some(Uint128(big.NewInt(1))), "Option (Uint128)", `{"constructor":"Some","argtypes":["Uint128"],"arguments":["1"]}`},
+			{None("ByStr20"), "Option (ByStr20)", `{"constructor":"None","argtypes":["ByStr20"],"arguments":[]}`},
+			{Pair(String("a"), Uint32(1)), "Pair (String) (Uint32)", `{"constructor":"Pair","argtypes":["String","Uint32"],"arguments":["a","1"]}`},
+			{List("Uint128"), "List (Uint128)", `[]`},
+			{List("Pair (String) (Uint32)", Pair(String("a"), Uint32(1))), "List (Pair (String) (Uint32))", `[{"constructor":"Pair","argtypes":["String","Uint32"],"arguments":["a","1"]}]`},
+			{Map("ByStr20", "Uint128", MapEntry{ByStr20("4baf5fada8e5db92c3d3242618c5b47133ae003c"), Uint128(big.NewInt(100))}), "Map (ByStr20) (Uint128)", `[{"key":"0x4baf5fada8e5db92c3d3242618c5b47133ae003c","val":"100"}]`},
+			{ADT("Colour", nil, "Red"), "Colour", `{"constructor":"Red","argtypes":[],"arguments":[]}`},
+		}
+		for _, c := range cases {
+			So(c.value.Type(), ShouldEqual, c.typ)
+			So(c.value.Validate(), ShouldBeNil)
+			data, err := json.Marshal(c.value)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, c.json)
+		}
+	})
+}
+
+func TestValue_Validate(t *testing.T) {
+	Convey("returns an error when the value does not fit its type", t, func() {
+		So(Uint128(new(big.Int).Lsh(big.NewInt(1), 128)).Validate(), ShouldNotBeNil)
+		So(Uint128(big.NewInt(-1)).Validate(), ShouldNotBeNil)
+		So(Int128(new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))).Validate(), ShouldBeNil)
+		So(ByStr20("0x4baf5fada8e5db92c3d3242618c5b47133ae00").Validate(), ShouldNotBeNil)
+		So(ByStr20("invalid address").Validate(), ShouldNotBeNil)
+		So(List("Uint128", Uint32(1)).Validate(), ShouldNotBeNil)
+		So(Map("ByStr20", "Uint128", MapEntry{String("a"), Uint128(big.NewInt(1))}).Validate(), ShouldNotBeNil)
+		So(Map("String", "Uint128", MapEntry{String("a"), Uint128(big.NewInt(1))}, MapEntry{String("a"), Uint128(big.NewInt(2))}).Validate(), ShouldNotBeNil)
+		So(ADT("Option", []string{"Uint128"}, "Some", String("a")).Validate(), ShouldNotBeNil)
+		So(ADT("Pair", []string{"String", "String"}, "Pair", String("a")).Validate(), ShouldNotBeNil)
+		So(Some(nil).Validate(), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,612 @@
+package zillean
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// maxSubStateRequests is the number of sub-state requests which GetMapEntries runs at once.
+const maxSubStateRequests = 8
+
+var (
+	bigIntType     = reflect.TypeOf(big.Int{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	adtValueType   = reflect.TypeOf(ADTValue{})
+)
+
+// ADTValue describes a value of a Scilla algebraic data type which has no native Go representation,
+// such as a user-defined ADT of a contract.
+type ADTValue struct {
+	Constructor string            `json:"constructor"`
+	ArgTypes    []string          `json:"argtypes"`
+	Arguments   []json.RawMessage `json:"arguments"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Value holds strings as they are
+// and other values, such as maps and ADTs, as JSON text; Raw holds the value as returned by the node.
+func (s *SmartContractState) UnmarshalJSON(data []byte) error {
+	var state struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+		Vname string          `json:"vname"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	s.Type = state.Type
+	s.Vname = state.Vname
+	s.Raw = state.Value
+	s.Value = ContractParam{Value: state.Value}.String()
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s SmartContractState) MarshalJSON() ([]byte, error) {
+	value := s.Raw
+	if value == nil {
+		var err error
+		if value, err = json.Marshal(s.Value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+		Vname string          `json:"vname"`
+	}{s.Type, value, s.Vname})
+}
+
+// Decode returns the state value as a Go value. See DecodeValue.
+func (s SmartContractState) Decode() (interface{}, error) {
+	return DecodeValue(s.Type, s.raw())
+}
+
+// Unmarshal decodes the state value into the Go value pointed to by v. See UnmarshalValue.
+func (s SmartContractState) Unmarshal(v interface{}) error {
+	return UnmarshalValue(s.Type, s.raw(), v)
+}
+
+func (s SmartContractState) raw() json.RawMessage {
+	if s.Raw == nil {
+		raw, _ := json.Marshal(s.Value)
+		return raw
+	}
+	return s.Raw
+}
+
+// Decode returns the param value as a Go value. See DecodeValue.
+func (p ContractParam) Decode() (interface{}, error) {
+	return DecodeValue(p.Type, p.Value)
+}
+
+// Unmarshal decodes the param value into the Go value pointed to by v. See UnmarshalValue.
+func (p ContractParam) Unmarshal(v interface{}) error {
+	return UnmarshalValue(p.Type, p.Value, v)
+}
+
+// Unmarshal decodes the params of the event into the struct pointed to by v, in the same way as the state
+// of a contract. See Unmarshal.
+func (l EventLog) Unmarshal(v interface{}) error {
+	state := make([]SmartContractState, len(l.Params))
+	for i, param := range l.Params {
+		state[i] = SmartContractState{Type: param.Type, Vname: param.Vname, Raw: param.Value}
+	}
+	return Unmarshal(state, v)
+}
+
+// Unmarshal decodes the state of a contract into the struct pointed to by v.
+// Each exported field is decoded from the state variable named by its scilla tag, or by the field name
+// if it has no tag. Fields tagged with "-" and fields without a state variable are left unchanged.
+//
+//	var state struct {
+//		TotalSupply *big.Int            `scilla:"total_supply"`
+//		Balances    map[string]*big.Int `scilla:"balances"`
+//	}
+//	err := zillean.Unmarshal(states, &state)
+func Unmarshal(state []SmartContractState, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("unmarshal target must be a non-nil pointer to a struct")
+	}
+	rv = rv.Elem()
+
+	vars := map[string]SmartContractState{}
+	for _, s := range state {
+		vars[s.Vname] = s
+	}
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		vname := field.Name
+		if tag, ok := field.Tag.Lookup("scilla"); ok {
+			vname = tag
+		}
+		if vname == "-" {
+			continue
+		}
+		s, ok := vars[vname]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(s.Type, s.raw(), rv.Field(i)); err != nil {
+			return fmt.Errorf("%s: %v", vname, err)
+		}
+	}
+	return nil
+}
+
+// GetMapEntry reads the entry of a map state variable of a smart contract address at keys, one per map level,
+// and decodes it as valType into the Go value pointed to by v. It reports false if the entry does not exist.
+//
+//	var balance *big.Int
+//	ok, err := rpc.GetMapEntry(tokenAddress, "balances", []zillean.Value{zillean.ByStr20(holder)}, "Uint128", &balance)
+func (r *RPC) GetMapEntry(contractAddress, variableName string, keys []Value, valType string, v interface{}) (bool, error) {
+	if len(keys) == 0 {
+		return false, errors.New("missing map keys")
+	}
+	indices, err := mapIndices(keys)
+	if err != nil {
+		return false, err
+	}
+	subState, err := r.GetSmartContractSubState(contractAddress, variableName, indices)
+	if err != nil {
+		return false, err
+	}
+
+	raw, ok := subState[variableName]
+	for _, index := range indices {
+		if !ok {
+			break
+		}
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return false, fmt.Errorf("invalid sub-state of %s: %s", variableName, raw)
+		}
+		raw, ok = entries[index]
+	}
+	if !ok {
+		return false, nil
+	}
+	return true, UnmarshalValue(valType, raw, v)
+}
+
+// GetMapEntries reads the entries of a map state variable of a smart contract address at many keys and decodes
+// them as valType into the Go map pointed to by v, whose keys are decoded from keys. Keys without an entry
+// are left out of the map. The entries are fetched with concurrent sub-state requests.
+//
+//	var balances map[string]*big.Int
+//	err := rpc.GetMapEntries(tokenAddress, "balances", holders, "Uint128", &balances)
+func (r *RPC) GetMapEntries(contractAddress, variableName string, keys []Value, valType string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Map {
+		return errors.New("unmarshal target must be a non-nil pointer to a map")
+	}
+	mapType := rv.Elem().Type()
+
+	type entry struct {
+		found bool
+		val   reflect.Value
+		err   error
+	}
+	entries := make([]entry, len(keys))
+	sem := make(chan struct{}, maxSubStateRequests)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, key Value) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			val := reflect.New(mapType.Elem())
+			found, err := r.GetMapEntry(contractAddress, variableName, []Value{key}, valType, val.Interface())
+			entries[i] = entry{found, val.Elem(), err}
+		}(i, key)
+	}
+	wg.Wait()
+
+	if rv.Elem().IsNil() {
+		rv.Elem().Set(reflect.MakeMapWithSize(mapType, len(keys)))
+	}
+	for i, e := range entries {
+		if e.err != nil {
+			return e.err
+		}
+		if !e.found {
+			continue
+		}
+		rawKey, _ := keys[i].MarshalJSON()
+		key := reflect.New(mapType.Key()).Elem()
+		if err := decodeValue(keys[i].Type(), rawKey, key); err != nil {
+			return err
+		}
+		rv.Elem().SetMapIndex(key, e.val)
+	}
+	return nil
+}
+
+// getStateVariable decodes a state variable of a smart contract address as valType into the Go value pointed to by v.
+func (r *RPC) getStateVariable(contractAddress, name, valType string, v interface{}) error {
+	subState, err := r.GetSmartContractSubState(contractAddress, name, nil)
+	if err != nil {
+		return err
+	}
+	raw, ok := subState[name]
+	if !ok {
+		return fmt.Errorf("contract %s has no %s", contractAddress, name)
+	}
+	return UnmarshalValue(valType, raw, v)
+}
+
+// mapIndices returns map keys in the string form used by GetSmartContractSubState.
+func mapIndices(keys []Value) ([]string, error) {
+	indices := make([]string, len(keys))
+	for i, key := range keys {
+		if key == nil {
+			return nil, fmt.Errorf("missing map key %d", i)
+		}
+		if err := key.Validate(); err != nil {
+			return nil, err
+		}
+		raw, err := key.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		indices[i] = ContractParam{Value: raw}.String()
+	}
+	return indices, nil
+}
+
+// DecodeValue returns a Scilla value in the JSON format as a Go value of the following types:
+//
+//	Uint32, ..., Int256, BNum    *big.Int
+//	String, ByStrX               string
+//	Bool                         bool
+//	Option (T)                   nil or the value of T
+//	Pair (A) (B)                 []interface{} of length 2
+//	List (T)                     []interface{}
+//	Map (K) (V)                  map[string]interface{}, keyed by the string form of K
+//	other ADTs                   ADTValue
+func DecodeValue(typ string, raw json.RawMessage) (interface{}, error) {
+	var v interface{}
+	if err := decodeValue(typ, raw, reflect.ValueOf(&v).Elem()); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// UnmarshalValue decodes a Scilla value in the JSON format into the Go value pointed to by v.
+// Besides the types returned by DecodeValue, integers can be decoded into Go integers, big.Int and strings,
+// Option into pointers (nil for None), Pair into structs with two fields and arrays,
+// List into slices and arrays, and Map into Go maps. json.RawMessage keeps the value as it is.
+func UnmarshalValue(typ string, raw json.RawMessage, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("unmarshal target must be a non-nil pointer")
+	}
+	return decodeValue(typ, raw, rv.Elem())
+}
+
+func decodeValue(typ string, raw json.RawMessage, rv reflect.Value) error {
+	head, args, err := splitType(typ)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case rv.Type() == rawMessageType:
+		rv.SetBytes(append(json.RawMessage{}, raw...))
+		return nil
+	case rv.Kind() == reflect.Interface && rv.NumMethod() == 0:
+		return decodeAny(head, args, raw, rv)
+	case rv.Kind() == reflect.Ptr:
+		if head == "Option" && len(args) == 1 {
+			adt, err := decodeADT(raw)
+			if err != nil {
+				return err
+			}
+			if adt.Constructor == "None" {
+				rv.Set(reflect.Zero(rv.Type()))
+				return nil
+			}
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		if head == "Option" && len(args) == 1 {
+			return decodeOption(args[0], raw, rv.Elem())
+		}
+		return decodeValue(typ, raw, rv.Elem())
+	}
+
+	switch {
+	case isIntType(head):
+		return decodeInt(typ, raw, rv)
+	case head == "String" || strings.HasPrefix(head, "ByStr"):
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return fmt.Errorf("invalid %s: %s", typ, raw)
+		}
+		if rv.Kind() != reflect.String {
+			return cannotDecode(typ, rv)
+		}
+		rv.SetString(s)
+		return nil
+	case head == "Bool" && len(args) == 0:
+		adt, err := decodeADT(raw)
+		if err != nil {
+			return err
+		}
+		if rv.Kind() != reflect.Bool {
+			return cannotDecode(typ, rv)
+		}
+		switch adt.Constructor {
+		case "True":
+			rv.SetBool(true)
+		case "False":
+			rv.SetBool(false)
+		default:
+			return fmt.Errorf("invalid Bool constructor %s", adt.Constructor)
+		}
+		return nil
+	case head == "Option" && len(args) == 1:
+		return decodeOption(args[0], raw, rv)
+	case head == "Pair" && len(args) == 2:
+		return decodePair(args, raw, rv)
+	case head == "List" && len(args) == 1:
+		return decodeList(args[0], raw, rv)
+	case head == "Map" && len(args) == 2:
+		return decodeMap(args[0], args[1], raw, rv)
+	case rv.Type() == adtValueType:
+		adt, err := decodeADT(raw)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(adt))
+		return nil
+	}
+	return cannotDecode(typ, rv)
+}
+
+// decodeAny decodes a value into an empty interface with the Go type chosen by DecodeValue.
+func decodeAny(head string, args []string, raw json.RawMessage, rv reflect.Value) error {
+	typ := applyType(head, args...)
+	var target reflect.Value
+	switch {
+	case isIntType(head):
+		target = reflect.New(reflect.TypeOf((*big.Int)(nil)))
+	case head == "String" || strings.HasPrefix(head, "ByStr"):
+		target = reflect.New(reflect.TypeOf(""))
+	case head == "Bool" && len(args) == 0:
+		target = reflect.New(reflect.TypeOf(false))
+	case head == "Option" && len(args) == 1:
+		adt, err := decodeADT(raw)
+		if err != nil {
+			return err
+		}
+		if adt.Constructor == "None" {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		return decodeOption(args[0], raw, rv)
+	case head == "Pair" && len(args) == 2, head == "List" && len(args) == 1:
+		target = reflect.New(reflect.TypeOf([]interface{}{}))
+	case head == "Map" && len(args) == 2:
+		target = reflect.New(reflect.TypeOf(map[string]interface{}{}))
+	default:
+		target = reflect.New(adtValueType)
+	}
+	if err := decodeValue(typ, raw, target.Elem()); err != nil {
+		return err
+	}
+	rv.Set(target.Elem())
+	return nil
+}
+
+func decodeInt(typ string, raw json.RawMessage, rv reflect.Value) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		// Integers are strings in the Scilla JSON format, but plain numbers are accepted as well.
+		s = strings.TrimSpace(string(raw))
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("invalid %s: %s", typ, raw)
+	}
+
+	switch {
+	case rv.Type() == bigIntType:
+		rv.Set(reflect.ValueOf(*n))
+	case rv.Kind() == reflect.String:
+		rv.SetString(n.String())
+	case rv.Kind() >= reflect.Int && rv.Kind() <= reflect.Int64:
+		if !n.IsInt64() || rv.OverflowInt(n.Int64()) {
+			return fmt.Errorf("%s overflows %s", n, rv.Type())
+		}
+		rv.SetInt(n.Int64())
+	case rv.Kind() >= reflect.Uint && rv.Kind() <= reflect.Uint64:
+		if !n.IsUint64() || rv.OverflowUint(n.Uint64()) {
+			return fmt.Errorf("%s overflows %s", n, rv.Type())
+		}
+		rv.SetUint(n.Uint64())
+	default:
+		return cannotDecode(typ, rv)
+	}
+	return nil
+}
+
+// decodeOption decodes an Option value into a non-pointer Go value, which is left as the zero value for None.
+func decodeOption(elemType string, raw json.RawMessage, rv reflect.Value) error {
+	adt, err := decodeADT(raw)
+	if err != nil {
+		return err
+	}
+	switch adt.Constructor {
+	case "None":
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	case "Some":
+		if len(adt.Arguments) != 1 {
+			return fmt.Errorf("Some takes 1 argument, got %d", len(adt.Arguments))
+		}
+		return decodeValue(elemType, adt.Arguments[0], rv)
+	}
+	return fmt.Errorf("invalid Option constructor %s", adt.Constructor)
+}
+
+func decodePair(argTypes []string, raw json.RawMessage, rv reflect.Value) error {
+	adt, err := decodeADT(raw)
+	if err != nil {
+		return err
+	}
+	if adt.Constructor != "Pair" || len(adt.Arguments) != 2 {
+		return fmt.Errorf("invalid Pair: %s", raw)
+	}
+
+	var first, second reflect.Value
+	switch rv.Kind() {
+	case reflect.Slice:
+		rv.Set(reflect.MakeSlice(rv.Type(), 2, 2))
+		first, second = rv.Index(0), rv.Index(1)
+	case reflect.Array:
+		if rv.Len() != 2 {
+			return cannotDecode(applyType("Pair", argTypes...), rv)
+		}
+		first, second = rv.Index(0), rv.Index(1)
+	case reflect.Struct:
+		var fields []reflect.Value
+		for i := 0; i < rv.NumField(); i++ {
+			if rv.Type().Field(i).PkgPath == "" {
+				fields = append(fields, rv.Field(i))
+			}
+		}
+		if len(fields) != 2 {
+			return cannotDecode(applyType("Pair", argTypes...), rv)
+		}
+		first, second = fields[0], fields[1]
+	default:
+		return cannotDecode(applyType("Pair", argTypes...), rv)
+	}
+	if err := decodeValue(argTypes[0], adt.Arguments[0], first); err != nil {
+		return err
+	}
+	return decodeValue(argTypes[1], adt.Arguments[1], second)
+}
+
+func decodeList(elemType string, raw json.RawMessage, rv reflect.Value) error {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil {
+		return fmt.Errorf("invalid %s: %s", applyType("List", elemType), raw)
+	}
+	switch rv.Kind() {
+	case reflect.Slice:
+		rv.Set(reflect.MakeSlice(rv.Type(), len(elems), len(elems)))
+	case reflect.Array:
+		if rv.Len() != len(elems) {
+			return fmt.Errorf("list of %d elements does not fit in %s", len(elems), rv.Type())
+		}
+	default:
+		return cannotDecode(applyType("List", elemType), rv)
+	}
+	for i, elem := range elems {
+		if err := decodeValue(elemType, elem, rv.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeMap decodes a Map, which nodes return either as a list of key-value pairs or as a JSON object.
+func decodeMap(keyType, valType string, raw json.RawMessage, rv reflect.Value) error {
+	typ := applyType("Map", keyType, valType)
+	if rv.Kind() != reflect.Map {
+		return cannotDecode(typ, rv)
+	}
+
+	var entries []struct {
+		Key json.RawMessage `json:"key"`
+		Val json.RawMessage `json:"val"`
+	}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return fmt.Errorf("invalid %s: %s", typ, raw)
+		}
+		for key, val := range object {
+			rawKey, _ := json.Marshal(key)
+			entries = append(entries, struct {
+				Key json.RawMessage `json:"key"`
+				Val json.RawMessage `json:"val"`
+			}{rawKey, val})
+		}
+	}
+
+	m := reflect.MakeMapWithSize(rv.Type(), len(entries))
+	for _, entry := range entries {
+		key := reflect.New(rv.Type().Key()).Elem()
+		if err := decodeValue(keyType, entry.Key, key); err != nil {
+			return err
+		}
+		val := reflect.New(rv.Type().Elem()).Elem()
+		if err := decodeValue(valType, entry.Val, val); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, val)
+	}
+	rv.Set(m)
+	return nil
+}
+
+func decodeADT(raw json.RawMessage) (ADTValue, error) {
+	var adt ADTValue
+	if err := json.Unmarshal(raw, &adt); err != nil || adt.Constructor == "" {
+		return ADTValue{}, fmt.Errorf("invalid ADT value: %s", bytes.TrimSpace(raw))
+	}
+	return adt, nil
+}
+
+func cannotDecode(typ string, rv reflect.Value) error {
+	return fmt.Errorf("cannot decode %s into %s", typ, rv.Type())
+}
+
+func isIntType(typ string) bool {
+	switch typ {
+	case "Uint32", "Uint64", "Uint128", "Uint256", "Int32", "Int64", "Int128", "Int256", "BNum":
+		return true
+	}
+	return false
+}
+
+// splitType splits a Scilla type into its name and type arguments, e.g. Map (ByStr20) (Uint128)
+// into Map and [ByStr20 Uint128].
+func splitType(typ string) (string, []string, error) {
+	tokens, err := tokenizeScilla(typ)
+	if err != nil {
+		return "", nil, err
+	}
+	head, group, rest, err := parseTypeAtom(tokens)
+	if err != nil {
+		return "", nil, err
+	}
+	if group {
+		return splitType(head)
+	}
+	var args []string
+	for len(rest) > 0 {
+		var arg string
+		if arg, _, rest, err = parseTypeAtom(rest); err != nil {
+			return "", nil, fmt.Errorf("invalid type %s: %v", typ, err)
+		}
+		args = append(args, arg)
+	}
+	return head, args, nil
+}
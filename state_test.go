@@ -0,0 +1,265 @@
+package zillean
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const tokenState = `[
+	{"vname": "_balance", "type": "Uint128", "value": "0"},
+	{"vname": "owner", "type": "ByStr20", "value": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},
+	{"vname": "total_supply", "type": "Uint128", "value": "340282366920938463463374607431768211455"},
+	{"vname": "decimals", "type": "Uint32", "value": "12"},
+	{"vname": "paused", "type": "Bool", "value": {"constructor": "False", "argtypes": [], "arguments": []}},
+	{"vname": "pending_owner", "type": "Option (ByStr20)",
+		"value": {"constructor": "Some", "argtypes": ["ByStr20"], "arguments": ["0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f"]}},
+	{"vname": "cap", "type": "Option (Uint128)", "value": {"constructor": "None", "argtypes": ["Uint128"], "arguments": []}},
+	{"vname": "minters", "type": "List (ByStr20)", "value": ["0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"]},
+	{"vname": "last_mint", "type": "Pair (BNum) (Uint128)",
+		"value": {"constructor": "Pair", "argtypes": ["BNum", "Uint128"], "arguments": ["73628", "100"]}},
+	{"vname": "balances", "type": "Map (ByStr20) (Uint128)",
+		"value": [{"key": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b", "val": "900"}, {"key": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "val": "100"}]},
+	{"vname": "allowances", "type": "Map (ByStr20) (Map (ByStr20) (Uint128))",
+		"value": {"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b": {"0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f": "50"}}},
+	{"vname": "status", "type": "Status", "value": {"constructor": "Active", "argtypes": [], "arguments": ["1"]}}
+]`
+
+func decodeTokenState() []SmartContractState {
+	var state []SmartContractState
+	So(json.Unmarshal([]byte(tokenState), &state), ShouldBeNil)
+	return state
+}
+
+func TestSmartContractState_UnmarshalJSON(t *testing.T) {
+	Convey("keeps the raw JSON value along with its string form", t, func() {
+		state := decodeTokenState()
+		So(state[1].Value, ShouldEqual, "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b")
+		So(string(state[1].Raw), ShouldEqual, `"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"`)
+		So(state[4].Value, ShouldEqual, `{"constructor": "False", "argtypes": [], "arguments": []}`)
+
+		data, err := json.Marshal(state[4])
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `{"type":"Bool","value":{"constructor":"False","argtypes":[],"arguments":[]},"vname":"paused"}`)
+	})
+}
+
+func TestDecodeValue(t *testing.T) {
+	Convey("returns Scilla values as Go values", t, func() {
+		state := decodeTokenState()
+		decoded := map[string]interface{}{}
+		for _, s := range state {
+			v, err := s.Decode()
+			So(err, ShouldBeNil)
+			decoded[s.Vname] = v
+		}
+
+		So(decoded["total_supply"].(*big.Int).String(), ShouldEqual, "340282366920938463463374607431768211455")
+		So(decoded["decimals"].(*big.Int).Int64(), ShouldEqual, 12)
+		So(decoded["owner"], ShouldEqual, "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b")
+		So(decoded["paused"], ShouldEqual, false)
+		So(decoded["pending_owner"], ShouldEqual, "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		So(decoded["cap"], ShouldBeNil)
+		So(decoded["minters"], ShouldResemble, []interface{}{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"})
+
+		pair := decoded["last_mint"].([]interface{})
+		So(pair[0].(*big.Int).Int64(), ShouldEqual, 73628)
+		So(pair[1].(*big.Int).Int64(), ShouldEqual, 100)
+
+		balances := decoded["balances"].(map[string]interface{})
+		So(len(balances), ShouldEqual, 2)
+		So(balances["0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f"].(*big.Int).Int64(), ShouldEqual, 100)
+
+		allowances := decoded["allowances"].(map[string]interface{})
+		spenders := allowances["0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"].(map[string]interface{})
+		So(spenders["0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f"].(*big.Int).Int64(), ShouldEqual, 50)
+
+		status := decoded["status"].(ADTValue)
+		So(status.Constructor, ShouldEqual, "Active")
+		So(len(status.Arguments), ShouldEqual, 1)
+	})
+
+	Convey("returns an error for a malformed value", t, func() {
+		_, err := DecodeValue("Uint128", json.RawMessage(`"12a"`))
+		So(err, ShouldNotBeNil)
+		_, err = DecodeValue("Bool", json.RawMessage(`"True"`))
+		So(err, ShouldNotBeNil)
+		_, err = DecodeValue("Map (ByStr20) (Uint128)", json.RawMessage(`"0"`))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestUnmarshalValue(t *testing.T) {
+	Convey("decodes Scilla values into typed Go values", t, func() {
+		var n uint32
+		So(UnmarshalValue("Uint32", json.RawMessage(`"12"`), &n), ShouldBeNil)
+		So(n, ShouldEqual, 12)
+
+		var s string
+		So(UnmarshalValue("Uint256", json.RawMessage(`"115792089237316195423570985008687907853269984665640564039457584007913129639935"`), &s), ShouldBeNil)
+		So(s, ShouldEqual, "115792089237316195423570985008687907853269984665640564039457584007913129639935")
+
+		var some *uint64
+		So(UnmarshalValue("Option (Uint64)", json.RawMessage(`{"constructor":"Some","argtypes":["Uint64"],"arguments":["7"]}`), &some), ShouldBeNil)
+		So(*some, ShouldEqual, 7)
+
+		var pair struct {
+			Block  uint64
+			Amount *big.Int
+		}
+		So(UnmarshalValue("Pair (BNum) (Uint128)", json.RawMessage(`{"constructor":"Pair","argtypes":["BNum","Uint128"],"arguments":["1","2"]}`), &pair), ShouldBeNil)
+		So(pair.Block, ShouldEqual, 1)
+		So(pair.Amount.Int64(), ShouldEqual, 2)
+
+		var raw json.RawMessage
+		So(UnmarshalValue("Status", json.RawMessage(`{"constructor":"Active"}`), &raw), ShouldBeNil)
+		So(string(raw), ShouldEqual, `{"constructor":"Active"}`)
+	})
+
+	Convey("returns an error when the value does not fit the Go type", t, func() {
+		var n int8
+		So(UnmarshalValue("Uint32", json.RawMessage(`"128"`), &n), ShouldNotBeNil)
+		var b bool
+		So(UnmarshalValue("String", json.RawMessage(`"true"`), &b), ShouldNotBeNil)
+		So(UnmarshalValue("String", json.RawMessage(`"true"`), b), ShouldNotBeNil)
+	})
+}
+
+func TestUnmarshal(t *testing.T) {
+	Convey("decodes the state of a contract into a struct by the scilla tags", t, func() {
+		var token struct {
+			Owner        string                         `scilla:"owner"`
+			TotalSupply  *big.Int                       `scilla:"total_supply"`
+			Decimals     uint32                         `scilla:"decimals"`
+			Paused       bool                           `scilla:"paused"`
+			PendingOwner *string                        `scilla:"pending_owner"`
+			Cap          *big.Int                       `scilla:"cap"`
+			Minters      []string                       `scilla:"minters"`
+			Balances     map[string]*big.Int            `scilla:"balances"`
+			Allowances   map[string]map[string]*big.Int `scilla:"allowances"`
+			Missing      string                         `scilla:"missing"`
+			Ignored      string                         `scilla:"-"`
+			Status       ADTValue                       `scilla:"status"`
+		}
+		token.Missing = "unchanged"
+		So(Unmarshal(decodeTokenState(), &token), ShouldBeNil)
+
+		So(token.Owner, ShouldEqual, "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b")
+		So(token.TotalSupply.String(), ShouldEqual, "340282366920938463463374607431768211455")
+		So(token.Decimals, ShouldEqual, 12)
+		So(token.Paused, ShouldBeFalse)
+		So(*token.PendingOwner, ShouldEqual, "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f")
+		So(token.Cap, ShouldBeNil)
+		So(token.Minters, ShouldResemble, []string{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"})
+		So(token.Balances["0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"].Int64(), ShouldEqual, 900)
+		So(token.Allowances["0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"]["0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f"].Int64(), ShouldEqual, 50)
+		So(token.Missing, ShouldEqual, "unchanged")
+		So(token.Status.Constructor, ShouldEqual, "Active")
+	})
+
+	Convey("returns an error naming the variable which cannot be decoded", t, func() {
+		var token struct {
+			Owner uint64 `scilla:"owner"`
+		}
+		err := Unmarshal(decodeTokenState(), &token)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, "owner:")
+		So(Unmarshal(decodeTokenState(), token), ShouldNotBeNil)
+	})
+}
+
+// newTokenNode returns a node stand-in which answers sub-state requests for the balances of a token contract.
+func newTokenNode(requests *int32) *httptest.Server {
+	balances := map[string]string{
+		"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b": "900",
+		"0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f": "100",
+	}
+	return newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+		atomic.AddInt32(requests, 1)
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		var variable string
+		var indices []string
+		json.Unmarshal(args[1], &variable)
+		json.Unmarshal(args[2], &indices)
+		if variable != "balances" {
+			return nil, ""
+		}
+		if len(indices) == 0 {
+			return map[string]interface{}{"balances": balances}, ""
+		}
+		balance, ok := balances[indices[0]]
+		if !ok {
+			return nil, ""
+		}
+		return map[string]interface{}{"balances": map[string]string{indices[0]: balance}}, ""
+	})
+}
+
+func TestRPC_GetMapEntry(t *testing.T) {
+	Convey("decodes the entry of a map state variable", t, func() {
+		var requests int32
+		node := newTokenNode(&requests)
+		defer node.Close()
+
+		var balance *big.Int
+		ok, err := NewRPC(node.URL).GetMapEntry("6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "balances",
+			[]Value{ByStr20("0xF49F1306BC8FB0CD8167A58A3550C1443072E96B")}, "Uint128", &balance)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		So(balance.Int64(), ShouldEqual, 900)
+	})
+
+	Convey("reports false when the entry does not exist", t, func() {
+		var requests int32
+		node := newTokenNode(&requests)
+		defer node.Close()
+
+		var balance *big.Int
+		ok, err := NewRPC(node.URL).GetMapEntry("6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "balances",
+			[]Value{ByStr20("0000000000000000000000000000000000000000")}, "Uint128", &balance)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+		So(balance, ShouldBeNil)
+	})
+
+	Convey("returns an error for an invalid key", t, func() {
+		var requests int32
+		node := newTokenNode(&requests)
+		defer node.Close()
+
+		var balance *big.Int
+		_, err := NewRPC(node.URL).GetMapEntry("6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "balances", []Value{ByStr20("0x1234")}, "Uint128", &balance)
+		So(err, ShouldNotBeNil)
+		So(requests, ShouldEqual, 0)
+	})
+}
+
+func TestRPC_GetMapEntries(t *testing.T) {
+	Convey("decodes the entries of a map state variable at many keys", t, func() {
+		var requests int32
+		node := newTokenNode(&requests)
+		defer node.Close()
+
+		keys := []Value{
+			ByStr20("f49f1306bc8fb0cd8167a58a3550c1443072e96b"),
+			ByStr20("6c1169e8a77d34d6d615862db5f62f0a9791cb9f"),
+			ByStr20("0000000000000000000000000000000000000000"),
+		}
+		var balances map[string]*big.Int
+		So(NewRPC(node.URL).GetMapEntries("6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "balances", keys, "Uint128", &balances), ShouldBeNil)
+		So(len(balances), ShouldEqual, 2)
+		So(balances["0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"].Int64(), ShouldEqual, 900)
+		So(balances["0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f"].Int64(), ShouldEqual, 100)
+		So(requests, ShouldEqual, 3)
+	})
+
+	Convey("returns an error when the target is not a map", t, func() {
+		var balances []*big.Int
+		So(NewRPC(localNet).GetMapEntries("6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "balances", nil, "Uint128", &balances), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,210 @@
+package zillean
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// msgVersion is the transaction message version, which is combined with the chain ID into RawTransaction.Version.
+	msgVersion = 1
+	// defaultTransferGasLimit is the gas limit of a non-contract transaction.
+	defaultTransferGasLimit = 1
+)
+
+// TxOptions describes the optional parameters for sending a transaction.
+// Zero values are filled in from the node.
+type TxOptions struct {
+	// Version defaults to the network ID of the node combined with the message version.
+	Version uint32
+	// Nonce defaults to the next nonce of the sender account.
+	Nonce uint64
+	// GasPrice defaults to the minimum gas price of the node.
+	GasPrice *big.Int
+	// GasLimit defaults to the gas limit suitable for the kind of transaction.
+	GasLimit uint64
+	// Wait describes how the transaction confirmation is polled.
+	Wait *WaitOptions
+}
+
+// TransferResult describes the outcome of a transfer.
+type TransferResult struct {
+	TxID     string
+	GasUsed  uint64
+	Success  bool
+	EpochNum uint64
+}
+
+// Transfer sends amount (in Qa) from the account of a private key to an address and waits for the confirmation.
+// If the transaction is submitted but not confirmed, the result holds its TxID along with the error. opts may be nil.
+func (z *Zillean) Transfer(ctx context.Context, privateKey, to, amount string, opts *TxOptions) (*TransferResult, error) {
+	rawTx, err := z.newTransfer(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	txID, result, err := z.sendTransaction(ctx, rawTx, privateKey, defaultTransferGasLimit, opts)
+	if err != nil {
+		if txID != "" {
+			return &TransferResult{TxID: txID}, err
+		}
+		return nil, err
+	}
+
+	gasUsed, _ := strconv.ParseUint(result.Transaction.Receipt.CumulativeGas, 10, 64)
+	return &TransferResult{
+		TxID:     txID,
+		GasUsed:  gasUsed,
+		Success:  result.Success,
+		EpochNum: result.EpochNum,
+	}, nil
+}
+
+// SignTransfer builds a transfer of amount (in Qa) from the account of a private key to an address and signs it
+// without submitting it. The unset options are filled in from the node as in Transfer. opts may be nil.
+func (z *Zillean) SignTransfer(privateKey, to, amount string, opts *TxOptions) (*RawTransaction, error) {
+	rawTx, err := z.newTransfer(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := z.prepareTransaction(&rawTx, privateKey, defaultTransferGasLimit, opts); err != nil {
+		return nil, err
+	}
+	rawTx.Signature, err = z.SignTransaction(rawTx, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &rawTx, nil
+}
+
+// newTransfer returns the raw transaction of a transfer with the recipient and amount validated.
+func (z *Zillean) newTransfer(to, amount string) (RawTransaction, error) {
+	to = strings.TrimPrefix(to, "0x")
+	if !z.IsAddress(to) {
+		return RawTransaction{}, errors.New("invalid address")
+	}
+	if err := checkAmount(amount); err != nil {
+		return RawTransaction{}, err
+	}
+	return RawTransaction{
+		To:     to,
+		Amount: amount,
+	}, nil
+}
+
+// checkAmount returns an error unless amount is a non-negative decimal integer. RawTransaction encodes the
+// magnitude of the amount only, so a negative amount would be sent as its absolute value.
+func checkAmount(amount string) error {
+	n, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return errors.New("invalid amount")
+	}
+	if n.Sign() < 0 {
+		return errors.New("negative amount")
+	}
+	return nil
+}
+
+// sendTransaction fills in the unset fields of a raw transaction, signs it, submits it and waits for the confirmation.
+// The TxID is returned whenever the transaction has been submitted.
+func (z *Zillean) sendTransaction(ctx context.Context, rawTx RawTransaction, privateKey string, gasLimit uint64, opts *TxOptions) (string, *TransactionResult, error) {
+	if err := z.prepareTransaction(&rawTx, privateKey, gasLimit, opts); err != nil {
+		return "", nil, err
+	}
+
+	signature, err := z.SignTransaction(rawTx, privateKey)
+	if err != nil {
+		return "", nil, err
+	}
+	txID, err := z.RPC.CreateTransaction(rawTx, signature)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var wait *WaitOptions
+	if opts != nil {
+		wait = opts.Wait
+	}
+	result, err := z.RPC.WaitForTransaction(ctx, txID, wait)
+	if err != nil {
+		return txID, nil, err
+	}
+	return txID, result, nil
+}
+
+func (z *Zillean) prepareTransaction(rawTx *RawTransaction, privateKey string, gasLimit uint64, opts *TxOptions) error {
+	o := TxOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	if ok, err := z.VerifyPrivateKey(privateKey); !ok {
+		return err
+	}
+	pubKey, err := z.GetPublicKeyFromPrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	rawTx.PubKey = pubKey
+
+	rawTx.Version = o.Version
+	if rawTx.Version == 0 {
+		networkID, err := z.RPC.GetNetworkID()
+		if err != nil {
+			return err
+		}
+		chainID, err := strconv.ParseUint(networkID, 10, 16)
+		if err != nil {
+			return err
+		}
+		rawTx.Version = uint32(chainID)<<16 | msgVersion
+	}
+
+	rawTx.Nonce = o.Nonce
+	if rawTx.Nonce == 0 {
+		address, err := z.GetAddressFromPrivateKey(privateKey)
+		if err != nil {
+			return err
+		}
+		nonce, err := z.nextNonce(address)
+		if err != nil {
+			return err
+		}
+		rawTx.Nonce = nonce
+	}
+
+	rawTx.GasPrice = o.GasPrice
+	if rawTx.GasPrice == nil {
+		minimumGasPrice, err := z.RPC.GetMinimumGasPrice()
+		if err != nil {
+			return err
+		}
+		gasPrice, ok := new(big.Int).SetString(minimumGasPrice, 10)
+		if !ok {
+			return errors.New("invalid minimum gas price")
+		}
+		rawTx.GasPrice = gasPrice
+	}
+
+	rawTx.GasLimit = o.GasLimit
+	if rawTx.GasLimit == 0 {
+		rawTx.GasLimit = gasLimit
+	}
+
+	return nil
+}
+
+// nextNonce returns the nonce of the next transaction sent from an address.
+func (z *Zillean) nextNonce(address string) (uint64, error) {
+	balance, err := z.RPC.GetBalance(address)
+	if err != nil {
+		// The node does not know accounts which have never received any funds.
+		if strings.Contains(strings.ToLower(err.Error()), "account is not created") {
+			return 1, nil
+		}
+		return 0, err
+	}
+	return uint64(balance.Nonce) + 1, nil
+}
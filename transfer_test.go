@@ -0,0 +1,108 @@
+package zillean
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newWalletNode returns a node stand-in which accepts every transaction, confirms it at epoch 68317
+// and passes the submitted raw transactions to sent.
+func newWalletNode(nonce int64, sent func(rawTx RawTransaction)) func(method string, params json.RawMessage) (interface{}, string) {
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		switch method {
+		case "GetNetworkId":
+			return "333", ""
+		case "GetBalance":
+			return map[string]interface{}{"balance": "3000000000000", "nonce": nonce}, ""
+		case "GetMinimumGasPrice":
+			return "1000000000", ""
+		case "CreateTransaction":
+			var rawTxs []RawTransaction
+			json.Unmarshal(params, &rawTxs)
+			sent(rawTxs[0])
+			return map[string]interface{}{"Info": "Non-contract txn, sent to shard", "TranID": "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5"}, ""
+		case "GetContractAddressFromTransactionID":
+			return "6c1169e8a77d34d6d615862db5f62f0a9791cb9f", ""
+		case "GetTransaction":
+			return map[string]interface{}{
+				"ID":      "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5",
+				"receipt": map[string]interface{}{"cumulative_gas": "1", "epoch_num": "68317", "success": true},
+			}, ""
+		}
+		return nil, "METHOD_NOT_FOUND: The method being requested is not available on this server"
+	}
+}
+
+func TestZillean_Transfer(t *testing.T) {
+	Convey("sends the amount and waits for the confirmation", t, func() {
+		var rawTx RawTransaction
+		node := newStubNode(newWalletNode(4, func(tx RawTransaction) { rawTx = tx }))
+		defer node.Close()
+
+		zil := NewZillean(node.URL)
+		result, err := zil.Transfer(context.Background(), testVectors[0].privateKey, "0xDf4B175C78e16EeBC05173E5C1f87355622D8104", "1000000000000", &TxOptions{Wait: fastWait})
+		So(err, ShouldBeNil)
+		So(result.TxID, ShouldEqual, "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5")
+		So(result.GasUsed, ShouldEqual, 1)
+		So(result.Success, ShouldBeTrue)
+		So(result.EpochNum, ShouldEqual, 68317)
+
+		So(rawTx.Version, ShouldEqual, 21823489)
+		So(rawTx.Nonce, ShouldEqual, 5)
+		So(rawTx.To, ShouldEqual, "Df4B175C78e16EeBC05173E5C1f87355622D8104")
+		So(rawTx.Amount, ShouldEqual, "1000000000000")
+		So(rawTx.PubKey, ShouldEqual, testVectors[0].publicKey)
+		So(rawTx.GasPrice.String(), ShouldEqual, "1000000000")
+		So(rawTx.GasLimit, ShouldEqual, 1)
+		So(rawTx.Signature, ShouldNotBeBlank)
+	})
+
+	Convey("returns an error when the recipient is not an address", t, func() {
+		_, err := NewZillean(localNet).Transfer(context.Background(), testVectors[0].privateKey, "invalid address", "1", nil)
+		So(err.Error(), ShouldEqual, "invalid address")
+	})
+
+	Convey("returns an error when the amount is negative", t, func() {
+		_, err := NewZillean(localNet).Transfer(context.Background(), testVectors[0].privateKey, "0xDf4B175C78e16EeBC05173E5C1f87355622D8104", "-1000", nil)
+		So(err.Error(), ShouldEqual, "negative amount")
+	})
+}
+
+func TestZillean_SignTransfer(t *testing.T) {
+	Convey("signs a transfer from an account the node does not know yet", t, func() {
+		wallet := newWalletNode(0, nil)
+		node := newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+			if method == "GetBalance" {
+				return nil, "Account is not created"
+			}
+			return wallet(method, params)
+		})
+		defer node.Close()
+
+		zil := NewZillean(node.URL)
+		rawTx, err := zil.SignTransfer(testVectors[0].privateKey, "0xDf4B175C78e16EeBC05173E5C1f87355622D8104", "1000000000000", nil)
+		So(err, ShouldBeNil)
+		So(rawTx.Version, ShouldEqual, 21823489)
+		So(rawTx.Nonce, ShouldEqual, 1)
+		So(rawTx.To, ShouldEqual, "Df4B175C78e16EeBC05173E5C1f87355622D8104")
+		So(rawTx.GasPrice.String(), ShouldEqual, "1000000000")
+		So(rawTx.GasLimit, ShouldEqual, 1)
+
+		signature, err := hex.DecodeString(rawTx.Signature)
+		So(err, ShouldBeNil)
+		r, s := signature[:32], signature[32:]
+		publicKey, _ := hex.DecodeString(rawTx.PubKey)
+		So(zil.VerifySignature(r, s, publicKey, encodeTransaction(*rawTx)), ShouldBeTrue)
+	})
+
+	Convey("returns an error when the amount is not an integer", t, func() {
+		_, err := NewZillean(localNet).SignTransfer(testVectors[0].privateKey, "0xDf4B175C78e16EeBC05173E5C1f87355622D8104", "1.5", nil)
+		So(err.Error(), ShouldEqual, "invalid amount")
+		_, err = NewZillean(localNet).SignTransfer(testVectors[0].privateKey, "0xDf4B175C78e16EeBC05173E5C1f87355622D8104", "-1000", nil)
+		So(err.Error(), ShouldEqual, "negative amount")
+	})
+}
@@ -0,0 +1,224 @@
+package zillean
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	crypto "github.com/GincoInc/go-crypto"
+	"github.com/golang/protobuf/proto"
+)
+
+// multiSigHashByte is prepended to the challenge hash of co-signatures, which Zilliqa domain-separates from
+// the hash of single signatures.
+const multiSigHashByte = 0x11
+
+// consensusQuorum is the fraction of the committee which has to co-sign a block.
+const consensusQuorum = 0.667
+
+// HeaderBytes returns the canonical serialization of the block header, which is the protobuf encoding of
+// ProtoTxBlock.TxBlockHeader hashed and co-signed by Zilliqa nodes.
+func (b *TxBlock) HeaderBytes() ([]byte, error) {
+	h := b.Header
+	committeeHash, err := hashBytes("CommitteeHash", h.CommitteeHash)
+	if err != nil {
+		return nil, err
+	}
+	prevHash, err := hashBytes("PrevBlockHash", h.PrevBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	stateRootHash, err := hashBytes("StateRootHash", h.StateRootHash)
+	if err != nil {
+		return nil, err
+	}
+	stateDeltaHash, err := hashBytes("StateDeltaHash", h.StateDeltaHash)
+	if err != nil {
+		return nil, err
+	}
+	mbInfoHash, err := hashBytes("MbInfoHash", h.MbInfoHash)
+	if err != nil {
+		return nil, err
+	}
+	minerPubKey, err := parsePublicKey(h.MinerPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rewards := h.Rewards.Int()
+	if rewards.Sign() < 0 || rewards.BitLen() > 128 {
+		return nil, fmt.Errorf("invalid Rewards %s", rewards)
+	}
+	if h.Version < 0 || h.Version > 0xffffffff || h.NumTxns < 0 || h.NumTxns > 0xffffffff {
+		return nil, errors.New("invalid version or NumTxns")
+	}
+
+	var base, hashSet, rewardsBytes, minerPubKeyBytes []byte
+	base = appendProtoVarint(base, 1, uint64(h.Version))
+	base = appendProtoBytes(base, 2, committeeHash)
+	base = appendProtoBytes(base, 3, prevHash)
+	hashSet = appendProtoBytes(hashSet, 1, stateRootHash)
+	hashSet = appendProtoBytes(hashSet, 2, stateDeltaHash)
+	hashSet = appendProtoBytes(hashSet, 3, mbInfoHash)
+	rewardsBytes = appendProtoBytes(rewardsBytes, 1, bigIntToPaddedBytes(rewards, 32))
+	minerPubKeyBytes = appendProtoBytes(minerPubKeyBytes, 1, minerPubKey)
+
+	var header []byte
+	header = appendProtoBytes(header, 1, base)
+	header = appendProtoVarint(header, 2, uint64(h.GasLimit))
+	header = appendProtoVarint(header, 3, uint64(h.GasUsed))
+	header = appendProtoBytes(header, 4, rewardsBytes)
+	header = appendProtoVarint(header, 5, uint64(h.BlockNum))
+	header = appendProtoBytes(header, 6, hashSet)
+	header = appendProtoVarint(header, 7, uint64(h.NumTxns))
+	header = appendProtoBytes(header, 8, minerPubKeyBytes)
+	header = appendProtoVarint(header, 9, uint64(h.DsBlockNum))
+	return header, nil
+}
+
+// Hash returns the hash of the block header, which is the BlockHash of the block and the PrevBlockHash of the next one.
+func (b *TxBlock) Hash() (string, error) {
+	header, err := b.HeaderBytes()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(crypto.Sha256(header)), nil
+}
+
+// VerifyHash checks whether the BlockHash of the block is the hash of its header.
+func (b *TxBlock) VerifyHash() error {
+	hash, err := b.Hash()
+	if err != nil {
+		return err
+	}
+	if b.Body.BlockHash.String() != hash {
+		return fmt.Errorf("block %d has BlockHash %s, but its header hashes to %s", b.Header.BlockNum, b.Body.BlockHash, hash)
+	}
+	return nil
+}
+
+// VerifyCoSignature checks the co-signature HeaderSign of the block against the public keys of the DS committee,
+// in committee order. The block has to be returned by GetTxBlockVerbose, which includes the bitmaps of the signers.
+//
+// Zilliqa co-signs a block in two rounds: CS1 is signed over the header by the members in B1, and HeaderSign over
+// the header, CS1 and B1 by the members in B2, each with the sum of the public keys of the signers.
+func (b *TxBlock) VerifyCoSignature(committee []string) error {
+	if len(b.Body.B1) == 0 || len(b.Body.B2) == 0 || b.Body.CS1 == "" {
+		return errors.New("block has no co-signature bitmaps, use GetTxBlockVerbose")
+	}
+	header, err := b.HeaderBytes()
+	if err != nil {
+		return err
+	}
+	cs1, err := parseSignature("CS1", b.Body.CS1)
+	if err != nil {
+		return err
+	}
+	cs2, err := parseSignature("HeaderSign", b.Body.HeaderSign)
+	if err != nil {
+		return err
+	}
+
+	ecs := NewECSchnorr()
+	if err := verifyMultiSig(ecs, committee, b.Body.B1, cs1, header); err != nil {
+		return fmt.Errorf("CS1: %v", err)
+	}
+	message := append(append(append([]byte{}, header...), cs1...), bitVectorBytes(b.Body.B1)...)
+	if err := verifyMultiSig(ecs, committee, b.Body.B2, cs2, message); err != nil {
+		return fmt.Errorf("HeaderSign: %v", err)
+	}
+	return nil
+}
+
+// verifyMultiSig checks a co-signature (r, s) over msg by the members of committee selected by bitmap.
+func verifyMultiSig(ecs *ECSchnorr, committee []string, bitmap []bool, sig, msg []byte) error {
+	if len(bitmap) != len(committee) {
+		return fmt.Errorf("bitmap has %d bits for a committee of %d", len(bitmap), len(committee))
+	}
+	var x, y *big.Int
+	signers := 0
+	for i, signed := range bitmap {
+		if !signed {
+			continue
+		}
+		pubKey, err := parsePublicKey(committee[i])
+		if err != nil {
+			return err
+		}
+		px, py := ecs.unmarshalPublicKey(pubKey)
+		if x == nil {
+			x, y = px, py
+		} else {
+			x, y = ecs.Curve.Add(x, y, px, py)
+		}
+		signers++
+	}
+	if quorum := int(math.Ceil(float64(len(committee)) * consensusQuorum)); signers < quorum {
+		return fmt.Errorf("signed by %d of %d members, %d required", signers, len(committee), quorum)
+	}
+
+	// The challenge is H(0x11, Q, pubKey, msg), otherwise the signature is verified as by ECSchnorr.Verify.
+	n := ecs.Curve.Params().N
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if r.Sign() == 0 || r.Cmp(n) >= 0 || s.Sign() == 0 || s.Cmp(n) >= 0 {
+		return errors.New("invalid signature")
+	}
+	pubKey := crypto.Compress(ecs.Curve, x, y)
+	rpkx, rpky := ecs.Curve.ScalarMult(x, y, sig[:32])
+	sGx, sGy := ecs.Curve.ScalarBaseMult(sig[32:])
+	Qx, Qy := ecs.Curve.Add(sGx, sGy, rpkx, rpky)
+	Q := crypto.Compress(ecs.Curve, Qx, Qy)
+	challenge := new(big.Int).SetBytes(crypto.Sha256(append(append(append([]byte{multiSigHashByte}, Q...), pubKey...), msg...)))
+	if challenge.Mod(challenge, n).Cmp(r) != 0 {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// bitVectorBytes returns the serialization of a bitmap by Zilliqa, which is the number of bits as 2 bytes
+// followed by the bits, most significant first.
+func bitVectorBytes(bits []bool) []byte {
+	b := make([]byte, 2+(len(bits)+7)/8)
+	binary.BigEndian.PutUint16(b, uint16(len(bits)))
+	for i, bit := range bits {
+		if bit {
+			b[2+i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return b
+}
+
+// hashBytes returns the bytes of a hash, which must be 32 bytes long.
+func hashBytes(name string, h Hash) ([]byte, error) {
+	if len(h) != 32 {
+		return nil, fmt.Errorf("invalid %s %s", name, h)
+	}
+	return h, nil
+}
+
+// parseSignature returns the 64 bytes (r, s) of a hex signature.
+func parseSignature(name, value string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(value), "0x"))
+	if err != nil || len(b) != 64 {
+		return nil, fmt.Errorf("invalid %s %s", name, value)
+	}
+	return b, nil
+}
+
+// appendProtoVarint appends a protobuf varint field to b.
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	b = append(b, proto.EncodeVarint(uint64(field)<<3)...)
+	return append(b, proto.EncodeVarint(v)...)
+}
+
+// appendProtoBytes appends a protobuf length-delimited field to b.
+func appendProtoBytes(b []byte, field int, data []byte) []byte {
+	b = append(b, proto.EncodeVarint(uint64(field)<<3|2)...)
+	b = append(b, proto.EncodeVarint(uint64(len(data)))...)
+	return append(b, data...)
+}
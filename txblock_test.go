@@ -0,0 +1,210 @@
+package zillean
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	crypto "github.com/GincoInc/go-crypto"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestTxBlock() *TxBlock {
+	var block TxBlock
+	block.Header.BlockNum = 100
+	block.Header.CommitteeHash = testHash(strings.Repeat("11", 32))
+	block.Header.DsBlockNum = 2
+	block.Header.GasLimit = 200000
+	block.Header.GasUsed = 0
+	block.Header.MbInfoHash = testHash("db311f58e5c43b043f9143c0b8efd62ceaf98eaeedf58b8b8c5300f0df780da1")
+	block.Header.MinerPubKey = "0x0238EA7FD93C9E0F30EB8F95BC2B22D7C998D76CFB1620172638B998A4BE01C5F0"
+	block.Header.NumTxns = 0
+	block.Header.PrevBlockHash = testHash("bb6ba0e008f272037c2fad24965a0b67380885ef1a853fe12d07714357a8f541")
+	block.Header.StateDeltaHash = testHash(strings.Repeat("00", 32))
+	block.Header.StateRootHash = testHash("c9065f6fd1520e6ed6174a2ae4c587acdfbd7346fcd4419d483cb5bb7b343ef5")
+	block.Header.Version = 1
+	return &block
+}
+
+// newTestCommittee returns the private and public keys of a committee of n members.
+func newTestCommittee(n int) ([][]byte, []string) {
+	ecs := NewECSchnorr()
+	privKeys := make([][]byte, n)
+	pubKeys := make([]string, n)
+	for i := range privKeys {
+		privKeys[i] = ecs.GeneratePrivateKey()
+		pubKeys[i] = fmt.Sprintf("0x%X", ecs.GetPublicKey(privKeys[i], true))
+	}
+	return privKeys, pubKeys
+}
+
+// coSign returns the co-signature of msg by the members of a committee selected by bitmap.
+func coSign(privKeys [][]byte, bitmap []bool, msg []byte) string {
+	ecs := NewECSchnorr()
+	n := ecs.Curve.Params().N
+	x := new(big.Int)
+	for i, signed := range bitmap {
+		if signed {
+			x.Add(x, new(big.Int).SetBytes(privKeys[i]))
+		}
+	}
+	x.Mod(x, n)
+	pubKey := ecs.GetPublicKey(x.Bytes(), true)
+
+	k := ecs.GeneratePrivateKey()
+	Qx, Qy := ecs.Curve.ScalarBaseMult(k)
+	Q := crypto.Compress(ecs.Curve, Qx, Qy)
+	r := new(big.Int).SetBytes(crypto.Sha256(append(append(append([]byte{multiSigHashByte}, Q...), pubKey...), msg...)))
+	r.Mod(r, n)
+	s := new(big.Int).Sub(new(big.Int).SetBytes(k), new(big.Int).Mul(r, x))
+	s.Mod(s, n)
+	return fmt.Sprintf("%064X%064X", r, s)
+}
+
+// signTestTxBlock sets the co-signatures of block by the members of a committee selected by b1 and b2.
+func signTestTxBlock(block *TxBlock, privKeys [][]byte, b1, b2 []bool) {
+	header, _ := block.HeaderBytes()
+	block.Body.B1 = b1
+	block.Body.B2 = b2
+	block.Body.CS1 = coSign(privKeys, b1, header)
+	cs1, _ := hex.DecodeString(block.Body.CS1)
+	block.Body.HeaderSign = coSign(privKeys, b2, append(append(header, cs1...), bitVectorBytes(b1)...))
+}
+
+func TestTxBlock_HeaderBytes(t *testing.T) {
+	Convey("returns the protobuf encoding of the block header", t, func() {
+		header, err := newTestTxBlock().HeaderBytes()
+		So(err, ShouldBeNil)
+		// blockheaderbase: version 1, committeehash and prevhash.
+		So(header[:4], ShouldResemble, []byte{0x0a, 0x46, 0x08, 0x01})
+		So(header[4:6], ShouldResemble, []byte{0x12, 0x20})
+		So(hex.EncodeToString(header[6:38]), ShouldEqual, strings.Repeat("11", 32))
+		// gaslimit 200000, gasused 0, rewards as a 16-byte ByteArray and blocknum 100.
+		So(header[72:80], ShouldResemble, []byte{0x10, 0xc0, 0x9a, 0x0c, 0x18, 0x00, 0x22, 0x12})
+		So(header[80:82], ShouldResemble, []byte{0x0a, 0x10})
+		So(header[98:100], ShouldResemble, []byte{0x28, 0x64})
+		// minerpubkey as a ByteArray holding the 33-byte compressed key, followed by dsblocknum 2.
+		minerPubKey, _ := hex.DecodeString("0238EA7FD93C9E0F30EB8F95BC2B22D7C998D76CFB1620172638B998A4BE01C5F0")
+		So(header[len(header)-39:len(header)-35], ShouldResemble, []byte{0x42, 0x23, 0x0a, 0x21})
+		So(header[len(header)-35:len(header)-2], ShouldResemble, minerPubKey)
+		So(header[len(header)-2:], ShouldResemble, []byte{0x48, 0x02})
+	})
+
+	Convey("returns an error if a field is invalid", t, func() {
+		block := newTestTxBlock()
+		block.Header.StateRootHash = testHash("c906")
+		_, err := block.HeaderBytes()
+		So(err, ShouldBeError, "invalid StateRootHash c906")
+
+		block = newTestTxBlock()
+		block.Header.Rewards.Int().SetInt64(-1)
+		_, err = block.HeaderBytes()
+		So(err, ShouldBeError, "invalid Rewards -1")
+	})
+}
+
+func TestTxBlock_Hash(t *testing.T) {
+	Convey("returns the SHA256 of the block header", t, func() {
+		block := newTestTxBlock()
+		header, _ := block.HeaderBytes()
+		hash, err := block.Hash()
+		So(err, ShouldBeNil)
+		So(hash, ShouldEqual, hex.EncodeToString(crypto.Sha256(header)))
+
+		block.Header.GasUsed = 1
+		other, _ := block.Hash()
+		So(other, ShouldNotEqual, hash)
+	})
+}
+
+func TestTxBlock_VerifyHash(t *testing.T) {
+	Convey("checks the BlockHash of the block", t, func() {
+		block := newTestTxBlock()
+		hash, _ := block.Hash()
+		block.Body.BlockHash = testHash(hash)
+		So(block.VerifyHash(), ShouldBeNil)
+
+		block.Header.NumTxns = 1
+		So(block.VerifyHash(), ShouldNotBeNil)
+	})
+}
+
+func TestTxBlock_VerifyCoSignature(t *testing.T) {
+	privKeys, committee := newTestCommittee(4)
+
+	Convey("accepts a block co-signed by the committee", t, func() {
+		block := newTestTxBlock()
+		signTestTxBlock(block, privKeys, []bool{true, true, true, true}, []bool{true, false, true, true})
+		So(block.VerifyCoSignature(committee), ShouldBeNil)
+	})
+
+	Convey("rejects a block whose header was changed", t, func() {
+		block := newTestTxBlock()
+		signTestTxBlock(block, privKeys, []bool{true, true, true, true}, []bool{true, true, true, true})
+		block.Header.StateRootHash = testHash(strings.Repeat("00", 32))
+		So(block.VerifyCoSignature(committee), ShouldBeError, "CS1: signature mismatch")
+	})
+
+	Convey("rejects a block co-signed by another committee", t, func() {
+		block := newTestTxBlock()
+		signTestTxBlock(block, privKeys, []bool{true, true, true, true}, []bool{true, true, true, true})
+		_, others := newTestCommittee(4)
+		So(block.VerifyCoSignature(others), ShouldBeError, "CS1: signature mismatch")
+	})
+
+	Convey("rejects a block whose second round bitmap was changed", t, func() {
+		block := newTestTxBlock()
+		signTestTxBlock(block, privKeys, []bool{true, true, true, true}, []bool{true, false, true, true})
+		block.Body.B2 = []bool{true, true, true, false}
+		So(block.VerifyCoSignature(committee), ShouldBeError, "HeaderSign: signature mismatch")
+	})
+
+	Convey("rejects a block co-signed by less than two thirds of the committee", t, func() {
+		block := newTestTxBlock()
+		signTestTxBlock(block, privKeys, []bool{true, true, true, true}, []bool{true, false, false, true})
+		So(block.VerifyCoSignature(committee), ShouldBeError, "HeaderSign: signed by 2 of 4 members, 3 required")
+	})
+
+	Convey("returns an error if the block has no bitmaps", t, func() {
+		So(newTestTxBlock().VerifyCoSignature(committee), ShouldBeError, "block has no co-signature bitmaps, use GetTxBlockVerbose")
+	})
+}
+
+// txBlockVector is a TX block returned by GetTxBlockVerbose of a Zilliqa node with the public keys of the DS
+// committee which co-signed it, in committee order.
+type txBlockVector struct {
+	Block     *TxBlock `json:"block"`
+	Committee []string `json:"committee"`
+}
+
+func TestTxBlock_vectors(t *testing.T) {
+	// The DS committee is not served by the JSON-RPC API, so the vectors are kept apart from the recorded fixtures.
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "txblock_vectors.json"))
+	if os.IsNotExist(err) {
+		t.Skip("testdata/txblock_vectors.json holds no TX blocks of a Zilliqa node")
+	}
+	Convey("hashes and verifies the co-signatures of TX blocks of a Zilliqa node", t, func() {
+		So(err, ShouldBeNil)
+		var vectors []txBlockVector
+		So(json.Unmarshal(data, &vectors), ShouldBeNil)
+		So(vectors, ShouldNotBeEmpty)
+		for _, v := range vectors {
+			hash, err := v.Block.Hash()
+			So(err, ShouldBeNil)
+			So(hash, ShouldEqual, v.Block.Body.BlockHash.String())
+			So(v.Block.VerifyCoSignature(v.Committee), ShouldBeNil)
+		}
+	})
+}
+
+func TestBitVectorBytes(t *testing.T) {
+	Convey("returns the number of bits followed by the bits", t, func() {
+		So(bitVectorBytes([]bool{true, false, true, true, false, false, false, false, true}), ShouldResemble, []byte{0x00, 0x09, 0xb0, 0x80})
+	})
+}
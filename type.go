@@ -1,6 +1,9 @@
 package zillean
 
-import "math/big"
+import (
+	"encoding/json"
+	"math/big"
+)
 
 // Balance describes the balance for an account.
 type Balance struct {
@@ -8,17 +11,21 @@ type Balance struct {
 	Nonce   int64  `json:"nonce"`
 }
 
-// DsBlock describes a DS-Block.
+// DsBlock describes a DS-Block. CommitteeHash, EpochNum, ShardingHash and Version are only returned by newer nodes.
 type DsBlock struct {
 	Header struct {
-		BlockNum     string   `json:"blockNum"`
-		Difficulty   int64    `json:"difficulty"`
-		DifficultyDS int64    `json:"difficultyDS"`
-		GasPrice     string   `json:"gasPrice"`
-		LeaderPubKey string   `json:"leaderPubKey"`
-		PoWWinners   []string `json:"powWinners"`
-		Prevhash     string   `json:"prevhash"`
-		Timestamp    string   `json:"timestamp"`
+		BlockNum      Number    `json:"blockNum"`
+		CommitteeHash Hash      `json:"committeeHash"`
+		Difficulty    int64     `json:"difficulty"`
+		DifficultyDS  int64     `json:"difficultyDS"`
+		EpochNum      *Number   `json:"epochNum"`
+		GasPrice      BigInt    `json:"gasPrice"`
+		LeaderPubKey  string    `json:"leaderPubKey"`
+		PoWWinners    []string  `json:"powWinners"`
+		Prevhash      Hash      `json:"prevhash"`
+		ShardingHash  Hash      `json:"shardingHash"`
+		Timestamp     Timestamp `json:"timestamp"`
+		Version       int64     `json:"version"`
 	} `json:"header"`
 	Signature string `json:"signature"`
 }
@@ -26,48 +33,100 @@ type DsBlock struct {
 // TxBlock describes a TX-Block.
 type TxBlock struct {
 	Body struct {
-		HeaderSign      string `json:"HeaderSign"`
+		BlockHash  Hash   `json:"BlockHash"`
+		HeaderSign string `json:"HeaderSign"`
+		// B1, B2 and CS1 are the co-signature bitmaps and first-round co-signature, which are only returned
+		// by GetTxBlockVerbose.
+		B1              []bool `json:"B1"`
+		B2              []bool `json:"B2"`
+		CS1             string `json:"CS1"`
 		MicroBlockInfos []struct {
-			MicroBlockHash        string `json:"MicroBlockHash"`
-			MicroBlockShardID     int64  `json:"MicroBlockShardId"`
-			MicroBlockTxnRootHash string `json:"MicroBlockTxnRootHash"`
+			MicroBlockHash        Hash  `json:"MicroBlockHash"`
+			MicroBlockShardID     int64 `json:"MicroBlockShardId"`
+			MicroBlockTxnRootHash Hash  `json:"MicroBlockTxnRootHash"`
 		} `json:"MicroBlockInfos"`
 	} `json:"body"`
 	Header struct {
-		BlockNum       string `json:"BlockNum"`
-		DsBlockNum     string `json:"DSBlockNum"`
-		GasLimit       string `json:"GasLimit"`
-		GasUsed        string `json:"GasUsed"`
-		MbInfoHash     string `json:"MbInfoHash"`
-		MinerPubKey    string `json:"MinerPubKey"`
-		NumMicroBlocks int64  `json:"NumMicroBlocks"`
-		NumTxns        int64  `json:"NumTxns"`
-		PrevBlockHash  string `json:"PrevBlockHash"`
-		Rewards        string `json:"Rewards"`
-		StateDeltaHash string `json:"StateDeltaHash"`
-		StateRootHash  string `json:"StateRootHash"`
-		Timestamp      string `json:"Timestamp"`
-		TxnHash        string `json:"TxnHash"`
-		Version        int64  `json:"version"`
+		BlockNum       Number    `json:"BlockNum"`
+		CommitteeHash  Hash      `json:"CommitteeHash"`
+		DsBlockNum     Number    `json:"DSBlockNum"`
+		GasLimit       Number    `json:"GasLimit"`
+		GasUsed        Number    `json:"GasUsed"`
+		MbInfoHash     Hash      `json:"MbInfoHash"`
+		MinerPubKey    string    `json:"MinerPubKey"`
+		NumMicroBlocks int64     `json:"NumMicroBlocks"`
+		NumTxns        int64     `json:"NumTxns"`
+		PrevBlockHash  Hash      `json:"PrevBlockHash"`
+		Rewards        BigInt    `json:"Rewards"`
+		StateDeltaHash Hash      `json:"StateDeltaHash"`
+		StateRootHash  Hash      `json:"StateRootHash"`
+		Timestamp      Timestamp `json:"Timestamp"`
+		TxnHash        Hash      `json:"TxnHash"`
+		Version        int64     `json:"version"`
 	} `json:"header"`
 }
 
 // Transaction describes a transaction object.
 type Transaction struct {
-	ID       string `json:"ID"`
-	Amount   string `json:"amount"`
-	GasLimit string `json:"gasLimit"`
-	GasPrice string `json:"gasPrice"`
-	Nonce    string `json:"nonce"`
-	Receipt  struct {
-		CumulativeGas string `json:"cumulative_gas"`
-		EpochNum      string `json:"epoch_num"`
-		Success       bool   `json:"success"`
-	} `json:"receipt"`
-	SenderPubKey string `json:"senderPubKey"`
-	Signature    string `json:"signature"`
-	ToAddr       string `json:"toAddr"`
-	Version      string `json:"version"`
+	ID           string             `json:"ID"`
+	Amount       string             `json:"amount"`
+	GasLimit     string             `json:"gasLimit"`
+	GasPrice     string             `json:"gasPrice"`
+	Nonce        string             `json:"nonce"`
+	Receipt      TransactionReceipt `json:"receipt"`
+	SenderPubKey string             `json:"senderPubKey"`
+	Signature    string             `json:"signature"`
+	ToAddr       string             `json:"toAddr"`
+	Version      string             `json:"version"`
+}
+
+// TransactionReceipt describes the receipt of a transaction.
+// EventLogs, Transitions, Exceptions and Errors are only set for smart contract transactions.
+type TransactionReceipt struct {
+	CumulativeGas string                 `json:"cumulative_gas"`
+	EpochNum      string                 `json:"epoch_num"`
+	Success       bool                   `json:"success"`
+	EventLogs     []EventLog             `json:"event_logs"`
+	Transitions   []Transition           `json:"transitions"`
+	Exceptions    []ReceiptException     `json:"exceptions"`
+	Errors        map[int][]ReceiptError `json:"errors"`
+}
+
+// EventLog describes an event emitted by a smart contract.
+type EventLog struct {
+	EventName string          `json:"_eventname"`
+	Address   string          `json:"address"`
+	Params    []ContractParam `json:"params"`
+}
+
+// Transition describes an internal message sent by a smart contract.
+type Transition struct {
+	Accepted bool              `json:"accepted"`
+	Addr     string            `json:"addr"`
+	Depth    int64             `json:"depth"`
+	Msg      TransitionMessage `json:"msg"`
+}
+
+// TransitionMessage describes the message of a transition.
+type TransitionMessage struct {
+	Amount    string          `json:"_amount"`
+	Recipient string          `json:"_recipient"`
+	Tag       string          `json:"_tag"`
+	Params    []ContractParam `json:"params"`
+}
+
+// ContractParam describes a named and typed Scilla value, as found in event logs and messages.
+// Value keeps the raw JSON since Scilla values can be strings, lists or ADT objects.
+type ContractParam struct {
+	Vname string          `json:"vname"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ReceiptException describes an exception thrown while running a smart contract.
+type ReceiptException struct {
+	Line    int64  `json:"line"`
+	Message string `json:"message"`
 }
 
 // RawTransaction describes a raw transaction object, which can be used in creating a new transaction.
@@ -86,14 +145,14 @@ type RawTransaction struct {
 
 // BlockchainInfo describes the information about Zilliqa blockchain.
 type BlockchainInfo struct {
-	CurrentDSEpoch    string            `json:"CurrentDSEpoch"`
-	CurrentMiniEpoch  string            `json:"CurrentMiniEpoch"`
+	CurrentDSEpoch    Number            `json:"CurrentDSEpoch"`
+	CurrentMiniEpoch  Number            `json:"CurrentMiniEpoch"`
 	DSBlockRate       float64           `json:"DSBlockRate"`
-	NumDSBlocks       string            `json:"NumDSBlocks"`
+	NumDSBlocks       Number            `json:"NumDSBlocks"`
 	NumPeers          int64             `json:"NumPeers"`
-	NumTransactions   string            `json:"NumTransactions"`
-	NumTxBlocks       string            `json:"NumTxBlocks"`
-	NumTxnsDSEpoch    string            `json:"NumTxnsDSEpoch"`
+	NumTransactions   Number            `json:"NumTransactions"`
+	NumTxBlocks       Number            `json:"NumTxBlocks"`
+	NumTxnsDSEpoch    Number            `json:"NumTxnsDSEpoch"`
 	NumTxnsTxEpoch    int64             `json:"NumTxnsTxEpoch"`
 	ShardingStructure ShardingStructure `json:"ShardingStructure"`
 	TransactionRate   int64             `json:"TransactionRate"`
@@ -113,11 +172,14 @@ type RecentTransactions struct {
 
 // ListedBlocks contains the paginated list of Blocks. This can be used for both DS-Blocks and TX-Blocks.
 type ListedBlocks struct {
-	Data []struct {
-		BlockNum int64  `json:"BlockNum"`
-		Hash     string `json:"Hash"`
-	} `json:"data"`
-	MaxPages int64 `json:"maxPages"`
+	Data     []ListedBlock `json:"data"`
+	MaxPages int64         `json:"maxPages"`
+}
+
+// ListedBlock describes a block in ListedBlocks.
+type ListedBlock struct {
+	BlockNum Number `json:"BlockNum"`
+	Hash     Hash   `json:"Hash"`
 }
 
 // SmartContract describes the smart contracts created by an address.
@@ -127,8 +189,11 @@ type SmartContract struct {
 }
 
 // SmartContractState describes the state of a smart contract.
+// Value is the string form of the value; Raw holds the value in the Scilla JSON format, which
+// can be decoded into Go values with Decode and Unmarshal.
 type SmartContractState struct {
-	Type  string `json:"type"`
-	Value string `json:"value"`
-	Vname string `json:"vname"`
+	Type  string          `json:"type"`
+	Value string          `json:"value"`
+	Vname string          `json:"vname"`
+	Raw   json.RawMessage `json:"-"`
 }
@@ -0,0 +1,98 @@
+package zillean
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WaitOptions describes how WaitForTransaction polls for a transaction.
+// Zero values are replaced with the defaults.
+type WaitOptions struct {
+	// Interval is the first polling interval. Defaults to 1 second.
+	Interval time.Duration
+	// MaxInterval caps the polling interval as it backs off. Defaults to 10 seconds.
+	MaxInterval time.Duration
+	// Multiplier grows the polling interval after every attempt. Defaults to 1.5.
+	Multiplier float64
+	// Timeout bounds the whole wait in addition to the context. No timeout by default.
+	Timeout time.Duration
+}
+
+// TransactionResult describes a transaction confirmed in a TX-Block.
+type TransactionResult struct {
+	Transaction *Transaction
+	Success     bool
+	EpochNum    uint64
+}
+
+func (opts *WaitOptions) withDefaults() WaitOptions {
+	o := WaitOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 10 * time.Second
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = o.Interval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1.5
+	}
+	return o
+}
+
+// WaitForTransaction polls GetTransaction with backoff until the transaction is confirmed,
+// the context is done or the timeout in opts expires.
+// A transaction the node does not know yet is treated as pending. opts may be nil.
+func (r *RPC) WaitForTransaction(ctx context.Context, txID string, opts *WaitOptions) (*TransactionResult, error) {
+	o := opts.withDefaults()
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	interval := o.Interval
+	for {
+		tx, err := r.GetTransaction(txID)
+		if err != nil && !isTxPending(err) {
+			return nil, err
+		}
+		if err == nil && tx.Receipt.EpochNum != "" {
+			epochNum, err := strconv.ParseUint(tx.Receipt.EpochNum, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return &TransactionResult{
+				Transaction: tx,
+				Success:     tx.Receipt.Success,
+				EpochNum:    epochNum,
+			}, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * o.Multiplier)
+		if interval > o.MaxInterval {
+			interval = o.MaxInterval
+		}
+	}
+}
+
+// isTxPending reports whether an error from GetTransaction means that the transaction is not confirmed yet.
+func isTxPending(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "txn hash not present") || strings.Contains(msg, "txn hash not found")
+}
@@ -0,0 +1,56 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var fastWait = &WaitOptions{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+
+func TestRPC_WaitForTransaction(t *testing.T) {
+	Convey("polls until the transaction is confirmed", t, func() {
+		calls := 0
+		node := newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+			calls++
+			if calls < 3 {
+				return nil, "Txn Hash not Present"
+			}
+			return map[string]interface{}{
+				"ID":      "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5",
+				"receipt": map[string]interface{}{"cumulative_gas": "1", "epoch_num": "68317", "success": true},
+			}, ""
+		})
+		defer node.Close()
+
+		result, err := NewRPC(node.URL).WaitForTransaction(context.Background(), "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5", fastWait)
+		So(err, ShouldBeNil)
+		So(calls, ShouldEqual, 3)
+		So(result.Success, ShouldBeTrue)
+		So(result.EpochNum, ShouldEqual, 68317)
+		So(result.Transaction.Receipt.CumulativeGas, ShouldEqual, "1")
+	})
+
+	Convey("returns the error of the node unless the transaction is pending", t, func() {
+		node := newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+			return nil, "INVALID_PARAMS: Invalid method parameters (invalid name and/or type) recognised"
+		})
+		defer node.Close()
+
+		_, err := NewRPC(node.URL).WaitForTransaction(context.Background(), "invalid", fastWait)
+		So(err.Error(), ShouldStartWith, "INVALID_PARAMS")
+	})
+
+	Convey("gives up when the timeout expires", t, func() {
+		node := newStubNode(func(method string, params json.RawMessage) (interface{}, string) {
+			return nil, "Txn Hash not Present"
+		})
+		defer node.Close()
+
+		_, err := NewRPC(node.URL).WaitForTransaction(context.Background(), "920f29f2985aac61637e82f7170f6ca465cc7e5495fecd53c808d63a98cbc8c5", &WaitOptions{Interval: time.Millisecond, Timeout: 20 * time.Millisecond})
+		So(err.Error(), ShouldEqual, context.DeadlineExceeded.Error())
+	})
+}
@@ -0,0 +1,266 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Queries of the WebSocket API.
+const (
+	wsQueryNewBlock    = "NewBlock"
+	wsQueryEventLog    = "EventLog"
+	wsQueryUnsubscribe = "Unsubscribe"
+)
+
+// NewBlockMessage is the notification of a new TX block sent by the WebSocket API.
+// TxHashes are the hashes of the transactions in the block, grouped by microblock.
+type NewBlockMessage struct {
+	TxBlock  TxBlock    `json:"TxBlock"`
+	TxHashes [][]string `json:"TxHashes"`
+}
+
+// EventLogMessage is the notification of the events emitted by a contract in a new TX block,
+// sent by the WebSocket API.
+type EventLogMessage struct {
+	Address   string     `json:"address"`
+	EventLogs []EventLog `json:"event_logs"`
+}
+
+type wsRequest struct {
+	Query     string   `json:"query"`
+	Addresses []string `json:"addresses,omitempty"`
+	Type      string   `json:"type,omitempty"`
+}
+
+type wsNotification struct {
+	Type   string `json:"type"`
+	Values []struct {
+		Query string          `json:"query"`
+		Value json.RawMessage `json:"value"`
+	} `json:"values"`
+}
+
+// WebSocket is a client of the WebSocket API of a Zilliqa node, which notifies subscribers of new TX blocks
+// and of the events of contracts. Subscriptions are kept across reconnections.
+type WebSocket struct {
+	URL    string
+	Dialer *websocket.Dialer
+	// ReconnectInterval is the first delay before reconnecting after the connection fails, which doubles
+	// up to MaxReconnectInterval. They default to 1s and 30s.
+	ReconnectInterval    time.Duration
+	MaxReconnectInterval time.Duration
+
+	blocks chan *NewBlockMessage
+	events chan *EventLogMessage
+	errors chan error
+
+	mu        sync.Mutex
+	conn      *websocket.Conn
+	newBlock  bool
+	addresses map[string]bool
+}
+
+// NewWebSocket returns a new WebSocket which connects to url, such as wss://api-ws.zilliqa.com.
+func NewWebSocket(url string) *WebSocket {
+	return &WebSocket{
+		URL:                  url,
+		Dialer:               websocket.DefaultDialer,
+		ReconnectInterval:    time.Second,
+		MaxReconnectInterval: 30 * time.Second,
+		blocks:               make(chan *NewBlockMessage),
+		events:               make(chan *EventLogMessage),
+		errors:               make(chan error, 16),
+		addresses:            map[string]bool{},
+	}
+}
+
+// NewBlocks returns the channel of new TX blocks, which is closed when Run returns.
+func (ws *WebSocket) NewBlocks() <-chan *NewBlockMessage { return ws.blocks }
+
+// EventLogs returns the channel of contract events, which is closed when Run returns.
+func (ws *WebSocket) EventLogs() <-chan *EventLogMessage { return ws.events }
+
+// Errors returns the channel of connection errors. Errors are dropped while the channel is full.
+func (ws *WebSocket) Errors() <-chan error { return ws.errors }
+
+// SubscribeNewBlock subscribes to new TX blocks. It may be called before Run.
+func (ws *WebSocket) SubscribeNewBlock() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.newBlock = true
+	return ws.send(wsRequest{Query: wsQueryNewBlock})
+}
+
+// UnsubscribeNewBlock cancels the subscription to new TX blocks.
+func (ws *WebSocket) UnsubscribeNewBlock() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.newBlock = false
+	return ws.send(wsRequest{Query: wsQueryUnsubscribe, Type: wsQueryNewBlock})
+}
+
+// SubscribeEventLog adds addresses to the contracts whose events are subscribed to. It may be called before Run.
+func (ws *WebSocket) SubscribeEventLog(addresses ...string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for _, address := range addresses {
+		ws.addresses[strings.ToLower(strings.TrimPrefix(address, "0x"))] = true
+	}
+	return ws.send(ws.eventLogRequest())
+}
+
+// UnsubscribeEventLog cancels the subscription to the events of all contracts.
+func (ws *WebSocket) UnsubscribeEventLog() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.addresses = map[string]bool{}
+	return ws.send(wsRequest{Query: wsQueryUnsubscribe, Type: wsQueryEventLog})
+}
+
+// Run connects to the node and delivers notifications until ctx is done, then closes the channels and returns
+// the error of ctx. The connection is reopened with backoff when it fails, and the subscriptions are sent again.
+// It must be called only once.
+func (ws *WebSocket) Run(ctx context.Context) error {
+	defer func() {
+		close(ws.blocks)
+		close(ws.events)
+		close(ws.errors)
+	}()
+
+	retry := ws.ReconnectInterval
+	for {
+		connected, err := ws.serve(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		select {
+		case ws.errors <- err:
+		default:
+		}
+		if connected {
+			retry = ws.ReconnectInterval
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > ws.MaxReconnectInterval {
+			retry = ws.MaxReconnectInterval
+		}
+	}
+}
+
+// serve opens a connection, subscribes, and reads notifications until the connection fails or ctx is done.
+// It reports whether the connection was opened.
+func (ws *WebSocket) serve(ctx context.Context) (bool, error) {
+	conn, _, err := ws.Dialer.Dial(ws.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	defer func() {
+		ws.mu.Lock()
+		ws.conn = nil
+		ws.mu.Unlock()
+		conn.Close()
+	}()
+
+	ws.mu.Lock()
+	ws.conn = conn
+	if ws.newBlock {
+		err = ws.send(wsRequest{Query: wsQueryNewBlock})
+	}
+	if err == nil && len(ws.addresses) > 0 {
+		err = ws.send(ws.eventLogRequest())
+	}
+	ws.mu.Unlock()
+	if err != nil {
+		return true, err
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		if err := ws.dispatch(ctx, data); err != nil {
+			return true, err
+		}
+	}
+}
+
+// dispatch delivers the values of a notification. Other messages, such as the acknowledgements of subscriptions,
+// are ignored.
+func (ws *WebSocket) dispatch(ctx context.Context, data []byte) error {
+	var notification wsNotification
+	if json.Unmarshal(data, &notification) != nil || notification.Type != "Notification" {
+		return nil
+	}
+	for _, value := range notification.Values {
+		switch value.Query {
+		case wsQueryNewBlock:
+			var block NewBlockMessage
+			if err := json.Unmarshal(value.Value, &block); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ws.blocks <- &block:
+			}
+		case wsQueryEventLog:
+			var messages []*EventLogMessage
+			if err := json.Unmarshal(value.Value, &messages); err != nil {
+				return err
+			}
+			for _, message := range messages {
+				ws.mu.Lock()
+				subscribed := ws.addresses[strings.ToLower(strings.TrimPrefix(message.Address, "0x"))]
+				ws.mu.Unlock()
+				if !subscribed {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case ws.events <- message:
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// send writes request to the connection if it is open. ws.mu must be held.
+func (ws *WebSocket) send(request wsRequest) error {
+	if ws.conn == nil {
+		return nil
+	}
+	return ws.conn.WriteJSON(request)
+}
+
+// eventLogRequest returns the subscription to the events of the subscribed contracts. ws.mu must be held.
+func (ws *WebSocket) eventLogRequest() wsRequest {
+	addresses := make([]string, 0, len(ws.addresses))
+	for address := range ws.addresses {
+		addresses = append(addresses, "0x"+address)
+	}
+	sort.Strings(addresses)
+	return wsRequest{Query: wsQueryEventLog, Addresses: addresses}
+}
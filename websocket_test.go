@@ -0,0 +1,101 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newWebSocketNode returns a stand-in of the WebSocket API which passes the requests of each connection to
+// requests, and answers each subscription with a notification. It closes the first connection after
+// the first notification.
+func newWebSocketNode(requests chan<- wsRequest) *httptest.Server {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		first := atomic.AddInt32(&connections, 1) == 1
+
+		for {
+			var request wsRequest
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			requests <- request
+			conn.WriteJSON(request)
+
+			var notification string
+			switch request.Query {
+			case wsQueryNewBlock:
+				notification = `{"type":"Notification","values":[{"query":"NewBlock","value":{"TxBlock":{"header":{"BlockNum":"100"}},"TxHashes":[["abc"]]}}]}`
+			case wsQueryEventLog:
+				notification = `{"type":"Notification","values":[{"query":"EventLog","value":[` +
+					`{"address":"0x1111111111111111111111111111111111111111","event_logs":[{"_eventname":"Other","params":[]}]},` +
+					`{"address":"0x` + tokenAddress + `","event_logs":[{"_eventname":"TransferSuccess","params":[{"vname":"amount","type":"Uint128","value":"5"}]}]}]}]}`
+			default:
+				continue
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(notification))
+			if first {
+				return
+			}
+		}
+	}))
+}
+
+func TestWebSocket(t *testing.T) {
+	Convey("delivers notifications of the subscriptions, and resubscribes after reconnecting", t, func() {
+		requests := make(chan wsRequest, 16)
+		node := newWebSocketNode(requests)
+		defer node.Close()
+
+		ws := NewWebSocket("ws" + strings.TrimPrefix(node.URL, "http"))
+		ws.ReconnectInterval = time.Millisecond
+		So(ws.SubscribeNewBlock(), ShouldBeNil)
+		So(ws.SubscribeEventLog("0x"+strings.ToUpper(tokenAddress)), ShouldBeNil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done := make(chan error)
+		go func() { done <- ws.Run(ctx) }()
+
+		block := <-ws.NewBlocks()
+		So(block.TxBlock.Header.BlockNum, ShouldEqual, Number(100))
+		So(block.TxHashes, ShouldResemble, [][]string{{"abc"}})
+		So(<-requests, ShouldResemble, wsRequest{Query: wsQueryNewBlock})
+
+		// The first connection is closed after the block, so the subscriptions are sent again.
+		So(<-ws.Errors(), ShouldNotBeNil)
+		So(<-requests, ShouldResemble, wsRequest{Query: wsQueryNewBlock})
+		So(<-requests, ShouldResemble, wsRequest{Query: wsQueryEventLog, Addresses: []string{"0x" + tokenAddress}})
+		So((<-ws.NewBlocks()).TxBlock.Header.BlockNum, ShouldEqual, Number(100))
+		message := <-ws.EventLogs()
+		So(message.Address, ShouldEqual, "0x"+tokenAddress)
+		So(message.EventLogs[0].EventName, ShouldEqual, "TransferSuccess")
+		var event struct {
+			Amount json.Number `scilla:"amount"`
+		}
+		So(message.EventLogs[0].Unmarshal(&event), ShouldBeNil)
+		So(event.Amount, ShouldEqual, "5")
+
+		So(ws.UnsubscribeEventLog(), ShouldBeNil)
+		So(<-requests, ShouldResemble, wsRequest{Query: wsQueryUnsubscribe, Type: wsQueryEventLog})
+
+		cancel()
+		So(<-done, ShouldEqual, context.Canceled)
+		_, ok := <-ws.EventLogs()
+		So(ok, ShouldBeFalse)
+	})
+}
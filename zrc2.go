@@ -0,0 +1,232 @@
+package zillean
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// ZRC2 represents a ZRC-2 fungible token contract.
+type ZRC2 struct {
+	Address string
+	zil     *Zillean
+
+	mu       sync.Mutex
+	decimals *uint32
+}
+
+// TransferEvent describes a TransferSuccess or TransferFromSuccess event of a ZRC-2 token.
+// Initiator is set for TransferFromSuccess only.
+type TransferEvent struct {
+	Initiator string   `scilla:"initiator"`
+	Sender    string   `scilla:"sender"`
+	Recipient string   `scilla:"recipient"`
+	Amount    *big.Int `scilla:"amount"`
+}
+
+// NewZRC2 returns a new ZRC2 bound to a token contract address.
+func NewZRC2(address string, zil *Zillean) *ZRC2 {
+	return &ZRC2{Address: strings.ToLower(strings.TrimPrefix(address, "0x")), zil: zil}
+}
+
+// BalanceOf returns the token balance of an address, which is zero if the address holds no tokens.
+func (t *ZRC2) BalanceOf(owner string) (*big.Int, error) {
+	var balance *big.Int
+	ok, err := t.zil.RPC.GetMapEntry(t.Address, "balances", []Value{ByStr20(owner)}, "Uint128", &balance)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return new(big.Int), nil
+	}
+	return balance, nil
+}
+
+// BalancesOf returns the token balances of many addresses, keyed by the lowercase 0x-prefixed address.
+// Addresses which hold no tokens have a zero balance.
+func (t *ZRC2) BalancesOf(owners []string) (map[string]*big.Int, error) {
+	keys := make([]Value, len(owners))
+	for i, owner := range owners {
+		keys[i] = ByStr20(owner)
+	}
+	var balances map[string]*big.Int
+	if err := t.zil.RPC.GetMapEntries(t.Address, "balances", keys, "Uint128", &balances); err != nil {
+		return nil, err
+	}
+	for _, owner := range owners {
+		key := "0x" + strings.ToLower(strings.TrimPrefix(owner, "0x"))
+		if balances[key] == nil {
+			balances[key] = new(big.Int)
+		}
+	}
+	return balances, nil
+}
+
+// TotalSupply returns the total supply of the token.
+func (t *ZRC2) TotalSupply() (*big.Int, error) {
+	var supply *big.Int
+	if err := t.zil.RPC.getStateVariable(t.Address, "total_supply", "Uint128", &supply); err != nil {
+		return nil, err
+	}
+	return supply, nil
+}
+
+// Decimals returns the number of decimals of the token, which is fetched once from the init parameters.
+func (t *ZRC2) Decimals() (uint32, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.decimals != nil {
+		return *t.decimals, nil
+	}
+
+	init, err := t.zil.RPC.GetSmartContractInit(t.Address)
+	if err != nil {
+		return 0, err
+	}
+	var params struct {
+		Decimals *uint32 `scilla:"decimals"`
+	}
+	if err := Unmarshal(init, &params); err != nil {
+		return 0, err
+	}
+	if params.Decimals == nil {
+		return 0, fmt.Errorf("contract %s has no decimals", t.Address)
+	}
+	t.decimals = params.Decimals
+	return *t.decimals, nil
+}
+
+// Allowance returns the amount which a spender is allowed to transfer from the tokens of an owner.
+func (t *ZRC2) Allowance(owner, spender string) (*big.Int, error) {
+	var allowance *big.Int
+	ok, err := t.zil.RPC.GetMapEntry(t.Address, "allowances", []Value{ByStr20(owner), ByStr20(spender)}, "Uint128", &allowance)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return new(big.Int), nil
+	}
+	return allowance, nil
+}
+
+// Transfer transfers amount tokens from the account of a private key to an address.
+// As with CallContract, a confirmed transfer which failed in the contract is not an error.
+func (t *ZRC2) Transfer(ctx context.Context, privateKey, to string, amount *big.Int, opts *TxOptions) (*ContractResult, error) {
+	return t.call(ctx, privateKey, "Transfer", opts,
+		Param{"to", ByStr20(to)},
+		Param{"amount", Uint128(amount)},
+	)
+}
+
+// TransferFrom transfers amount tokens from an address to another, using the allowance of the account of a private key.
+func (t *ZRC2) TransferFrom(ctx context.Context, privateKey, from, to string, amount *big.Int, opts *TxOptions) (*ContractResult, error) {
+	return t.call(ctx, privateKey, "TransferFrom", opts,
+		Param{"from", ByStr20(from)},
+		Param{"to", ByStr20(to)},
+		Param{"amount", Uint128(amount)},
+	)
+}
+
+// IncreaseAllowance increases the allowance of a spender on the tokens of the account of a private key.
+func (t *ZRC2) IncreaseAllowance(ctx context.Context, privateKey, spender string, amount *big.Int, opts *TxOptions) (*ContractResult, error) {
+	return t.call(ctx, privateKey, "IncreaseAllowance", opts,
+		Param{"spender", ByStr20(spender)},
+		Param{"amount", Uint128(amount)},
+	)
+}
+
+// DecreaseAllowance decreases the allowance of a spender on the tokens of the account of a private key.
+func (t *ZRC2) DecreaseAllowance(ctx context.Context, privateKey, spender string, amount *big.Int, opts *TxOptions) (*ContractResult, error) {
+	return t.call(ctx, privateKey, "DecreaseAllowance", opts,
+		Param{"spender", ByStr20(spender)},
+		Param{"amount", Uint128(amount)},
+	)
+}
+
+func (t *ZRC2) call(ctx context.Context, privateKey, transition string, opts *TxOptions, params ...Param) (*ContractResult, error) {
+	return t.zil.CallContract(ctx, privateKey, t.Address, transition, params, "0", opts)
+}
+
+// FormatAmount returns an amount in the smallest unit as a decimal string in token units, e.g. 1.5.
+func (t *ZRC2) FormatAmount(amount *big.Int) (string, error) {
+	decimals, err := t.Decimals()
+	if err != nil {
+		return "", err
+	}
+	return FormatUnits(amount, decimals)
+}
+
+// ParseAmount returns a decimal string in token units as an amount in the smallest unit.
+func (t *ZRC2) ParseAmount(amount string) (*big.Int, error) {
+	decimals, err := t.Decimals()
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnits(amount, decimals)
+}
+
+// TransferEvents returns the TransferSuccess and TransferFromSuccess events of the token in a receipt.
+func (t *ZRC2) TransferEvents(receipt TransactionReceipt) ([]TransferEvent, error) {
+	var events []TransferEvent
+	for _, log := range receipt.EventLogs {
+		if log.EventName != "TransferSuccess" && log.EventName != "TransferFromSuccess" {
+			continue
+		}
+		if strings.ToLower(strings.TrimPrefix(log.Address, "0x")) != t.Address {
+			continue
+		}
+		var event TransferEvent
+		if err := log.Unmarshal(&event); err != nil {
+			return nil, fmt.Errorf("%s: %v", log.EventName, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// FormatUnits returns an amount in the smallest unit as a decimal string with decimals digits after the point,
+// dropping trailing zeros, e.g. 1500000 with 6 decimals as 1.5. Token amounts cannot be negative.
+func FormatUnits(amount *big.Int, decimals uint32) (string, error) {
+	if amount == nil {
+		return "", errors.New("missing amount")
+	}
+	if amount.Sign() < 0 {
+		return "", fmt.Errorf("negative amount %s", amount)
+	}
+	digits := amount.String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(decimals)
+	s := digits[:point]
+	if fraction := strings.TrimRight(digits[point:], "0"); fraction != "" {
+		s += "." + fraction
+	}
+	return s, nil
+}
+
+// ParseUnits returns a decimal string with up to decimals digits after the point as an amount in the smallest unit,
+// e.g. 1.5 with 6 decimals as 1500000. Token amounts cannot be negative.
+func ParseUnits(s string, decimals uint32) (*big.Int, error) {
+	if strings.HasPrefix(s, "-") {
+		return nil, fmt.Errorf("negative amount %s", s)
+	}
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if len(fraction) > int(decimals) {
+		return nil, fmt.Errorf("%s has more than %d decimals", s, decimals)
+	}
+	if whole == "" || strings.ContainsAny(whole[1:]+fraction, "+-") {
+		return nil, fmt.Errorf("invalid amount %s", s)
+	}
+	n, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %s", s)
+	}
+	return n, nil
+}
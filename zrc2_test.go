@@ -0,0 +1,199 @@
+package zillean
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const tokenAddress = "6c1169e8a77d34d6d615862db5f62f0a9791cb9f"
+
+// newZRC2Node returns a node stand-in of a ZRC-2 token with 6 decimals, which also accepts every transaction
+// like newWalletNode.
+func newZRC2Node(sent func(rawTx RawTransaction)) func(method string, params json.RawMessage) (interface{}, string) {
+	wallet := newWalletNode(0, sent)
+	return func(method string, params json.RawMessage) (interface{}, string) {
+		switch method {
+		case "GetSmartContractInit":
+			return []map[string]string{
+				{"vname": "name", "type": "String", "value": "Zillean Token"},
+				{"vname": "decimals", "type": "Uint32", "value": "6"},
+			}, ""
+		case "GetSmartContractSubState":
+			var args []json.RawMessage
+			json.Unmarshal(params, &args)
+			var variable string
+			var indices []string
+			json.Unmarshal(args[1], &variable)
+			json.Unmarshal(args[2], &indices)
+			switch {
+			case variable == "total_supply":
+				return map[string]string{"total_supply": "1000000000000"}, ""
+			case variable == "balances" && indices[0] == "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b":
+				return map[string]interface{}{"balances": map[string]string{indices[0]: "1500000"}}, ""
+			case variable == "allowances" && indices[0] == "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b":
+				return map[string]interface{}{"allowances": map[string]interface{}{indices[0]: map[string]string{indices[1]: "250"}}}, ""
+			}
+			return nil, ""
+		}
+		return wallet(method, params)
+	}
+}
+
+func TestZRC2_State(t *testing.T) {
+	Convey("returns the balances, the total supply, the decimals and the allowances of the token", t, func() {
+		node := newStubNode(newZRC2Node(nil))
+		defer node.Close()
+		token := NewZRC2("0x"+tokenAddress, NewZillean(node.URL))
+
+		balance, err := token.BalanceOf("F49F1306BC8FB0CD8167A58A3550C1443072E96B")
+		So(err, ShouldBeNil)
+		So(balance.Int64(), ShouldEqual, 1500000)
+
+		balance, err = token.BalanceOf("0000000000000000000000000000000000000000")
+		So(err, ShouldBeNil)
+		So(balance.Sign(), ShouldEqual, 0)
+
+		balances, err := token.BalancesOf([]string{"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b", "0000000000000000000000000000000000000000"})
+		So(err, ShouldBeNil)
+		So(balances["0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"].Int64(), ShouldEqual, 1500000)
+		So(balances["0x0000000000000000000000000000000000000000"].Sign(), ShouldEqual, 0)
+
+		supply, err := token.TotalSupply()
+		So(err, ShouldBeNil)
+		So(supply.String(), ShouldEqual, "1000000000000")
+
+		decimals, err := token.Decimals()
+		So(err, ShouldBeNil)
+		So(decimals, ShouldEqual, 6)
+
+		allowance, err := token.Allowance("f49f1306bc8fb0cd8167a58a3550c1443072e96b", "4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		So(err, ShouldBeNil)
+		So(allowance.Int64(), ShouldEqual, 250)
+
+		formatted, err := token.FormatAmount(balance.SetInt64(1500000))
+		So(err, ShouldBeNil)
+		So(formatted, ShouldEqual, "1.5")
+		amount, err := token.ParseAmount("0.25")
+		So(err, ShouldBeNil)
+		So(amount.Int64(), ShouldEqual, 250000)
+	})
+}
+
+func TestZRC2_Transfer(t *testing.T) {
+	Convey("calls the transitions of the token", t, func() {
+		var rawTx RawTransaction
+		node := newStubNode(newZRC2Node(func(tx RawTransaction) { rawTx = tx }))
+		defer node.Close()
+		token := NewZRC2(tokenAddress, NewZillean(node.URL))
+		opts := &TxOptions{Wait: fastWait}
+
+		result, err := token.Transfer(context.Background(), testVectors[0].privateKey, "4BAF5FADA8E5DB92C3D3242618C5B47133AE003C", big.NewInt(1000), opts)
+		So(err, ShouldBeNil)
+		So(result.Success, ShouldBeTrue)
+		So(rawTx.To, ShouldEqual, tokenAddress)
+		So(rawTx.Amount, ShouldEqual, "0")
+		So(rawTx.Data, ShouldEqual, `{"_tag":"Transfer","params":[{"vname":"to","type":"ByStr20","value":"0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},`+
+			`{"vname":"amount","type":"Uint128","value":"1000"}]}`)
+
+		_, err = token.TransferFrom(context.Background(), testVectors[0].privateKey, "f49f1306bc8fb0cd8167a58a3550c1443072e96b", "4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(5), opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldEqual, `{"_tag":"TransferFrom","params":[{"vname":"from","type":"ByStr20","value":"0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},`+
+			`{"vname":"to","type":"ByStr20","value":"0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},{"vname":"amount","type":"Uint128","value":"5"}]}`)
+
+		_, err = token.IncreaseAllowance(context.Background(), testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(7), opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldContainSubstring, `"_tag":"IncreaseAllowance"`)
+
+		_, err = token.DecreaseAllowance(context.Background(), testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", big.NewInt(7), opts)
+		So(err, ShouldBeNil)
+		So(rawTx.Data, ShouldContainSubstring, `"_tag":"DecreaseAllowance"`)
+	})
+
+	Convey("returns an error when the amount is missing", t, func() {
+		_, err := NewZRC2(tokenAddress, NewZillean(localNet)).Transfer(context.Background(), testVectors[0].privateKey, "4baf5fada8e5db92c3d3242618c5b47133ae003c", nil, nil)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestZRC2_TransferEvents(t *testing.T) {
+	Convey("returns the transfer events of the token in a receipt", t, func() {
+		var receipt TransactionReceipt
+		So(json.Unmarshal([]byte(`{"event_logs": [
+			{"_eventname": "TransferSuccess", "address": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "params": [
+				{"vname": "sender", "type": "ByStr20", "value": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},
+				{"vname": "recipient", "type": "ByStr20", "value": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},
+				{"vname": "amount", "type": "Uint128", "value": "1000"}]},
+			{"_eventname": "TransferSuccess", "address": "0x0000000000000000000000000000000000000001", "params": []},
+			{"_eventname": "TransferFromSuccess", "address": "0x6c1169e8a77d34d6d615862db5f62f0a9791cb9f", "params": [
+				{"vname": "initiator", "type": "ByStr20", "value": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},
+				{"vname": "sender", "type": "ByStr20", "value": "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b"},
+				{"vname": "recipient", "type": "ByStr20", "value": "0x4baf5fada8e5db92c3d3242618c5b47133ae003c"},
+				{"vname": "amount", "type": "Uint128", "value": "5"}]}
+		], "success": true}`), &receipt), ShouldBeNil)
+
+		events, err := NewZRC2(tokenAddress, nil).TransferEvents(receipt)
+		So(err, ShouldBeNil)
+		So(len(events), ShouldEqual, 2)
+		So(events[0].Sender, ShouldEqual, "0xf49f1306bc8fb0cd8167a58a3550c1443072e96b")
+		So(events[0].Recipient, ShouldEqual, "0x4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		So(events[0].Amount.Int64(), ShouldEqual, 1000)
+		So(events[0].Initiator, ShouldBeBlank)
+		So(events[1].Initiator, ShouldEqual, "0x4baf5fada8e5db92c3d3242618c5b47133ae003c")
+		So(events[1].Amount.Int64(), ShouldEqual, 5)
+	})
+}
+
+func TestFormatUnits(t *testing.T) {
+	Convey("returns an amount in token units", t, func() {
+		for amount, expected := range map[int64]string{1500000: "1.5", 1: "0.000001", 0: "0", 2000000: "2"} {
+			s, err := FormatUnits(big.NewInt(amount), 6)
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, expected)
+		}
+		s, err := FormatUnits(big.NewInt(42), 0)
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "42")
+	})
+
+	Convey("returns an error for a missing or negative amount", t, func() {
+		_, err := FormatUnits(nil, 6)
+		So(err, ShouldBeError, "missing amount")
+		_, err = FormatUnits(big.NewInt(-2000000), 6)
+		So(err, ShouldBeError, "negative amount -2000000")
+	})
+}
+
+func TestParseUnits(t *testing.T) {
+	Convey("returns an amount in the smallest unit", t, func() {
+		n, err := ParseUnits("1.5", 6)
+		So(err, ShouldBeNil)
+		So(n.Int64(), ShouldEqual, 1500000)
+		n, err = ParseUnits("0.000001", 6)
+		So(err, ShouldBeNil)
+		So(n.Int64(), ShouldEqual, 1)
+		n, err = ParseUnits("42", 0)
+		So(err, ShouldBeNil)
+		So(n.Int64(), ShouldEqual, 42)
+	})
+
+	Convey("returns an error for an invalid amount", t, func() {
+		_, err := ParseUnits("0.0000001", 6)
+		So(err, ShouldNotBeNil)
+		_, err = ParseUnits("1.2.3", 6)
+		So(err, ShouldNotBeNil)
+		_, err = ParseUnits("abc", 6)
+		So(err, ShouldNotBeNil)
+		_, err = ParseUnits("", 6)
+		So(err, ShouldNotBeNil)
+		_, err = ParseUnits("1.-5", 6)
+		So(err, ShouldNotBeNil)
+		_, err = ParseUnits("+-1", 6)
+		So(err, ShouldNotBeNil)
+		_, err = ParseUnits("-1.5", 6)
+		So(err, ShouldBeError, "negative amount -1.5")
+	})
+}